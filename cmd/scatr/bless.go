@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/deepsourcelabs/SCATR/runner"
+	"github.com/spf13/cobra"
+)
+
+var prune bool
+
+var blessCmd = &cobra.Command{
+	Use:   "bless",
+	Short: "Update the golden files using the actual Autofix output",
+	Long: "Runs the Autofix script and writes the Autofix'ed content of each file to " +
+		"its golden file. Golden files are created for the files without one only " +
+		"if the Autofix changed them. The golden files of the files which the Autofix " +
+		"no longer changes are reported as stale, and are only removed with --prune. " +
+		"The original files are restored afterwards.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := os.Chdir(runCwd)
+		if err != nil {
+			return err
+		}
+
+		if !verbose {
+			log.SetOutput(io.Discard)
+		}
+
+		ctx, stop := signalContext()
+		defer stop()
+
+		updates, err := runner.Bless(ctx, files, autofixDir, timeout, prune)
+		if err != nil {
+			fmt.Println(err)
+			// skipcq: RVV-A0003
+			os.Exit(exitCode(err))
+		}
+
+		if len(updates) == 0 {
+			fmt.Println("All golden files are up to date")
+			return nil
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		for _, update := range updates {
+			file := update.File
+			if rel, err := filepath.Rel(cwd, file); err == nil {
+				file = rel
+			}
+
+			if update.Status == runner.GoldenFileStale {
+				fmt.Printf("Warn: %s is stale as the Autofix no longer changes its file, use --prune to remove it\n", file)
+				continue
+			}
+
+			fmt.Printf("%s %s\n", update.Status, file)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	blessCmd.Flags().StringVarP(
+		&runCwd, "cwd", "c", ".",
+		"Set the current working directory of the runner.",
+	)
+	blessCmd.Flags().BoolVarP(
+		&verbose, "verbose", "v", false,
+		"Use verbose logging",
+	)
+	blessCmd.Flags().StringArrayVarP(
+		&files, "files", "f", []string{},
+		"Set the list of files to update the golden files for. This is relative to the specified cwd.",
+	)
+	blessCmd.Flags().StringVarP(
+		&autofixDir, "autofix-dir", "a", "",
+		"Sets the directory where the Autofix takes place. It uses the cwd if nothing is specified.",
+	)
+
+	blessCmd.Flags().BoolVar(
+		&prune, "prune", false,
+		"Remove the golden files of the files which the Autofix no longer changes.",
+	)
+
+	rootCmd.AddCommand(blessCmd)
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// preset is the scaffolding configuration for a language.
+type preset struct {
+	files         string
+	commentPrefix []string
+	ext           string
+
+	// The sample fixture is made of the lines before the line with the sample
+	// issue, the line with the issue, and the lines after it. The golden file is
+	// the fixture without the line with the issue.
+	before []string
+	issue  string
+	after  []string
+}
+
+var presets = map[string]*preset{
+	"go": {
+		files:         "**/*.go",
+		commentPrefix: []string{"//"},
+		ext:           "go",
+		before:        []string{"package main", "", "func main() {", "\ta := 10"},
+		issue:         "\ta = a",
+		after:         []string{"\t_ = a", "}"},
+	},
+	"python": {
+		files:         "**/*.py",
+		commentPrefix: []string{"#"},
+		ext:           "py",
+		before:        []string{"def main():", "    a = 10"},
+		issue:         "    a = a",
+		after:         []string{"    print(a)"},
+	},
+	"javascript": {
+		files:         "**/*.{js,jsx,mjs,cjs}",
+		commentPrefix: []string{"//"},
+		ext:           "js",
+		before:        []string{"let a = 10;"},
+		issue:         "a = a;",
+		after:         []string{"console.log(a);"},
+	},
+	"java": {
+		files:         "**/*.java",
+		commentPrefix: []string{"//"},
+		ext:           "java",
+		before:        []string{"class Sample {", "    void sample() {", "        int a = 10;"},
+		issue:         "        a = a;",
+		after:         []string{"        System.out.println(a);", "    }", "}"},
+	},
+	"cpp": {
+		files:         "**/*.{c,cc,cpp,cxx,h,hh,hpp}",
+		commentPrefix: []string{"//"},
+		ext:           "cpp",
+		before:        []string{"int main() {", "    int a = 10;"},
+		issue:         "    a = a;",
+		after:         []string{"    return a;", "}"},
+	},
+	"ruby": {
+		files:         "**/*.rb",
+		commentPrefix: []string{"#"},
+		ext:           "rb",
+		before:        []string{"def sample", "  a = 10"},
+		issue:         "  a = a",
+		after:         []string{"  puts a", "end"},
+	},
+	"rust": {
+		files:         "**/*.rs",
+		commentPrefix: []string{"//"},
+		ext:           "rs",
+		before:        []string{"fn main() {", "    let mut a = 10;"},
+		issue:         "    a = a;",
+		after:         []string{"    println!(\"{}\", a);", "}"},
+	},
+}
+
+const (
+	sampleIssueCode  = "SAMPLE-001"
+	sampleIssueTitle = "Self-assignment of a"
+)
+
+const configTemplate = `files = %q
+comment_prefix = [%s]
+code_path = ""
+excluded_dirs = []
+
+[checks]
+# Replace the script with the command which runs the analyzer on the files in
+# $CODE_PATH, and writes its result to the output_file. The sample script writes
+# the issue expected by the pragma in %[3]s.
+script = """
+cat > analysis_result.json <<'EOF'
+%[4]s
+EOF
+"""
+interpreter = "sh"
+output_file = "analysis_result.json"
+
+[processor]
+# The processor converts the output_file, available in the INPUT_FILE
+# environment variable, to the SCATR result format and prints it on stdout.
+skip_processing = false
+script = """
+cat $INPUT_FILE
+"""
+interpreter = "sh"
+
+[autofix]
+# Replace the script with the command which runs the Autofix on the files in
+# $CODE_PATH, and writes the Autofix'ed files to $OUTPUT_DIR. The sample script
+# copies the golden file of %[3]s.
+script = """
+cp %[3]s.golden "$OUTPUT_DIR/%[3]s"
+"""
+interpreter = "sh"
+`
+
+const analysisResultTemplate = `{
+  "issues": [
+    {
+      "code": %q,
+      "title": %q,
+      "position": {
+        "file": %q,
+        "start": { "line": %d, "column": %d }
+      }
+    }
+  ]
+}`
+
+var (
+	initLang  string
+	initForce bool
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Scaffold a .scatr.toml and a sample fixture for a language",
+	Long: "Writes a .scatr.toml with the files glob and the comment prefix of the language, " +
+		"along with a sample fixture containing a pragma and its golden file, so that " +
+		"`scatr run` works right after scaffolding. Supported languages: " +
+		strings.Join(presetNames(), ", ") + ".",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		p, ok := presets[initLang]
+		if !ok {
+			return fmt.Errorf(
+				"unsupported language %q, supported languages: %s",
+				initLang, strings.Join(presetNames(), ", "),
+			)
+		}
+
+		err := os.Chdir(runCwd)
+		if err != nil {
+			return err
+		}
+
+		files := p.scaffold()
+
+		names := make([]string, 0, len(files))
+		for name := range files {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if !initForce {
+			for _, name := range names {
+				_, err := os.Stat(name)
+				if err == nil {
+					return fmt.Errorf("%s already exists, use --force to overwrite it", name)
+				}
+				if !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+			}
+		}
+
+		for _, name := range names {
+			err := os.WriteFile(name, []byte(files[name]), 0o644)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println("Created", filepath.Clean(name))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	initCmd.Flags().StringVarP(
+		&runCwd, "cwd", "c", ".",
+		"Set the directory to scaffold the files in.",
+	)
+	initCmd.Flags().StringVarP(
+		&initLang, "lang", "l", "",
+		"Set the language of the fixtures. One of: "+strings.Join(presetNames(), ", "),
+	)
+	initCmd.Flags().BoolVar(
+		&initForce, "force", false,
+		"Overwrite the existing files",
+	)
+	_ = initCmd.MarkFlagRequired("lang")
+
+	rootCmd.AddCommand(initCmd)
+}
+
+func presetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// scaffold returns the content of the files to write, mapped by their names.
+func (p *preset) scaffold() map[string]string {
+	fixture := "sample." + p.ext
+
+	issueLine := len(p.before) + 1
+	issueColumn := len(p.issue) - len(strings.TrimLeft(p.issue, " \t")) + 1
+
+	pragma := fmt.Sprintf("%s [%s]: %d %q", p.commentPrefix[0], sampleIssueCode, issueColumn, sampleIssueTitle)
+
+	var fixtureLines []string
+	fixtureLines = append(fixtureLines, p.before...)
+	fixtureLines = append(fixtureLines, p.issue+" "+pragma)
+	fixtureLines = append(fixtureLines, p.after...)
+
+	var goldenLines []string
+	goldenLines = append(goldenLines, p.before...)
+	goldenLines = append(goldenLines, p.after...)
+
+	prefixes := make([]string, 0, len(p.commentPrefix))
+	for _, prefix := range p.commentPrefix {
+		prefixes = append(prefixes, fmt.Sprintf("%q", prefix))
+	}
+
+	analysisResult := fmt.Sprintf(
+		analysisResultTemplate,
+		sampleIssueCode, sampleIssueTitle, fixture, issueLine, issueColumn,
+	)
+
+	return map[string]string{
+		".scatr.toml":       fmt.Sprintf(configTemplate, p.files, strings.Join(prefixes, ", "), fixture, analysisResult),
+		fixture:             strings.Join(fixtureLines, "\n") + "\n",
+		fixture + ".golden": strings.Join(goldenLines, "\n") + "\n",
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/deepsourcelabs/SCATR/runner"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Report the malformed pragmas in the files",
+	Long: "Reports the comments which look like a pragma but are not fully parsed, and the " +
+		"scatr-check / scatr-ignore directives which are not on line 1. Exits with a non-zero " +
+		"status if anything is found.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := os.Chdir(runCwd)
+		if err != nil {
+			return err
+		}
+
+		if !verbose {
+			log.SetOutput(io.Discard)
+		}
+
+		findings, err := runner.Lint(files)
+		if err != nil {
+			fmt.Println(err)
+			// skipcq: RVV-A0003
+			os.Exit(1)
+		}
+
+		if len(findings) == 0 {
+			fmt.Println("No malformed pragmas found")
+			return nil
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		for _, finding := range findings {
+			if rel, err := filepath.Rel(cwd, finding.File); err == nil {
+				finding.File = rel
+			}
+
+			fmt.Println(finding)
+		}
+
+		// skipcq: RVV-A0003
+		os.Exit(1)
+		return nil
+	},
+}
+
+func init() {
+	lintCmd.Flags().StringVarP(
+		&runCwd, "cwd", "c", ".",
+		"Set the current working directory of the runner.",
+	)
+	lintCmd.Flags().BoolVarP(
+		&verbose, "verbose", "v", false,
+		"Use verbose logging",
+	)
+	lintCmd.Flags().StringArrayVarP(
+		&files, "files", "f", []string{},
+		"Set the list of files to lint. This is relative to the specified cwd.",
+	)
+
+	rootCmd.AddCommand(lintCmd)
+}
@@ -82,8 +82,23 @@ type MarvinIssue struct {
 	Location  MarvinLocation `json:"location" msgpack:"location"`
 }
 
+type MarvinError struct {
+	HMessage string `json:"hmessage" msgpack:"hmessage"`
+	Level    int    `json:"level" msgpack:"level"`
+}
+
+type MarvinMetric struct {
+	MetricCode string `json:"metric_code" msgpack:"metric_code"`
+	Namespaces []struct {
+		Key   string  `json:"key" msgpack:"key"`
+		Value float64 `json:"value" msgpack:"value"`
+	} `json:"namespaces" msgpack:"namespaces"`
+}
+
 type MarvinResult struct {
-	Issues []MarvinIssue `json:"issues" msgpack:"issues"`
+	Issues  []MarvinIssue  `json:"issues" msgpack:"issues"`
+	Errors  []MarvinError  `json:"errors" msgpack:"errors"`
+	Metrics []MarvinMetric `json:"metrics" msgpack:"metrics"`
 }
 
 func unmarshalMarvinResult(isMsgpack bool, file string) (*MarvinResult, error) {
@@ -114,7 +129,15 @@ func unmarshalMarvinResult(isMsgpack bool, file string) (*MarvinResult, error) {
 }
 
 type Result struct {
-	Issues []*Issue `json:"issues"`
+	Issues  []*Issue  `json:"issues"`
+	Errors  []string  `json:"errors,omitempty"`
+	Metrics []*Metric `json:"metrics,omitempty"`
+}
+
+type Metric struct {
+	Code      string  `json:"code"`
+	Namespace string  `json:"namespace,omitempty"`
+	Value     float64 `json:"value"`
 }
 
 type Issue struct {
@@ -154,5 +177,30 @@ func marvinResultToResult(result *MarvinResult) *Result {
 		})
 	}
 
+	// The errors and metrics are passed on to the runner, which handles the
+	// errors as per the errors policy of the processor. The level of an error
+	// is kept in its message, as the runner handles all the errors alike.
+	for _, e := range result.Errors {
+		log.Printf("Analyzer error (level %d): %s\n", e.Level, e.HMessage)
+		res.Errors = append(res.Errors, fmt.Sprintf("%s (level %d)", e.HMessage, e.Level))
+	}
+
+	for _, metric := range result.Metrics {
+		// The values of a metric are in its namespaces, so a metric without
+		// any namespace has no value to pass on.
+		if len(metric.Namespaces) == 0 {
+			log.Printf("Skipping the metric %s without any namespace\n", metric.MetricCode)
+			continue
+		}
+
+		for _, namespace := range metric.Namespaces {
+			res.Metrics = append(res.Metrics, &Metric{
+				Code:      metric.MetricCode,
+				Namespace: namespace.Key,
+				Value:     namespace.Value,
+			})
+		}
+	}
+
 	return &res
 }
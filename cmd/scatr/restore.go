@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/deepsourcelabs/SCATR/runner"
+	"github.com/spf13/cobra"
+)
+
+var restoreForce bool
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore the files left Autofix'ed by an interrupted in-place run",
+	Long: "Restores the files of an in-place Autofix run which was killed before restoring " +
+		"them, using the journal it left for the code path. The journal also stops other " +
+		"in-place runs in the same code path until the files are restored.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := os.Chdir(runCwd)
+		if err != nil {
+			return err
+		}
+
+		if !verbose {
+			log.SetOutput(io.Discard)
+		}
+
+		restored, err := runner.Restore(restoreForce)
+		if err != nil {
+			fmt.Println(err)
+			// skipcq: RVV-A0003
+			os.Exit(1)
+		}
+
+		if restored == nil {
+			fmt.Println("Nothing to restore")
+			return nil
+		}
+
+		for _, file := range restored {
+			fmt.Println("Restored", file)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	restoreCmd.Flags().StringVarP(
+		&runCwd, "cwd", "c", ".",
+		"Set the current working directory of the runner.",
+	)
+	restoreCmd.Flags().BoolVarP(
+		&verbose, "verbose", "v", false,
+		"Use verbose logging",
+	)
+	restoreCmd.Flags().BoolVar(
+		&restoreForce, "force", false,
+		"Restore the files even if the run which wrote the journal seems to be in progress",
+	)
+
+	rootCmd.AddCommand(restoreCmd)
+}
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -19,3 +24,29 @@ var rootCmd = &cobra.Command{
 		return nil
 	},
 }
+
+var timeout time.Duration
+
+func init() {
+	rootCmd.PersistentFlags().DurationVar(
+		&timeout, "timeout", 0,
+		"Set the timeout of each script, like 10m. The timeout configured for a stage in the "+
+			".scatr.toml takes precedence. There is no timeout by default.",
+	)
+}
+
+// signalContext returns a context which is cancelled on SIGINT and SIGTERM, so
+// that the running script is killed and the files are restored before exiting.
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// exitCode returns the exit code for a runner error.
+func exitCode(err error) int {
+	if errors.Is(err, context.Canceled) {
+		// The conventional exit code for SIGINT.
+		return 130
+	}
+
+	return 1
+}
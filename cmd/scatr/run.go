@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -17,31 +19,60 @@ var (
 	verbose    bool
 	files      []string
 	autofixDir string
+	format     string
+	outputFile string
+	lint       bool
+	jobs       int
 )
 
 var runCmd = &cobra.Command{
-	Use:   "run",
+	Use:   "run [suites...]",
 	Short: "Run the tests in a provided directory",
+	Long: "Runs the tests of the suite in the cwd. In case suites are provided, each of them is " +
+		"run, followed by a summary of the results. A suite is a directory with a .scatr.toml, " +
+		"and a path ending with /..., like ./..., runs every suite in the tree rooted at it. " +
+		"Use --jobs to run multiple suites concurrently.",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		err := os.Chdir(runCwd)
 		if err != nil {
 			return err
 		}
 
-		var printer runner.IssuePrinter
-		if pretty {
-			printer = runner.NewPrettyIssuePrinter()
-		} else {
-			printer = &runner.DefaultIssuePrinter{}
+		printer, closeOutput, err := newPrinter()
+		if err != nil {
+			return err
 		}
 
 		if !verbose {
 			log.SetOutput(io.Discard)
 		}
 
-		passed, err := runner.Run(printer, files, autofixDir)
+		ctx, stop := signalContext()
+		defer stop()
+
+		var passed bool
+		if len(args) == 0 {
+			passed, err = runner.Run(ctx, runOptions(printer))
+		} else {
+			passed, err = runSuites(ctx, printer, args)
+		}
 		if err != nil {
-			fmt.Println(err)
+			// The report is still written, along with the error, so that the tools
+			// parsing it see the failed run.
+			if p, ok := printer.(runner.ErrorPrinter); ok {
+				p.PrintError(err)
+			}
+			if err := flushPrinter(printer, closeOutput); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+			}
+
+			fmt.Fprintln(os.Stderr, err)
+			// skipcq: RVV-A0003
+			os.Exit(exitCode(err))
+		}
+
+		if err := flushPrinter(printer, closeOutput); err != nil {
+			fmt.Fprintln(os.Stderr, err)
 			// skipcq: RVV-A0003
 			os.Exit(1)
 		}
@@ -81,5 +112,130 @@ func init() {
 			"It does not clean up the directory in case autofix-dir is set.",
 	)
 
+	runCmd.Flags().StringVar(
+		&format, "format", "text",
+		"Set the output format. Supported formats are text, github, junit, json and sarif.",
+	)
+	runCmd.Flags().StringVarP(
+		&outputFile, "output", "o", "",
+		"Write the report to the provided file instead of stdout. The text output is "+
+			"still printed on stdout in this case. It is not supported with the text and github formats.",
+	)
+	runCmd.Flags().BoolVar(
+		&lint, "lint", false,
+		"Lint the pragmas before running the tests, and fail the run in case any malformed "+
+			"pragma is found",
+	)
+
+	runCmd.Flags().IntVarP(
+		&jobs, "jobs", "j", 1,
+		"Set the number of suites to run concurrently. The output of each suite is printed once "+
+			"it completes.",
+	)
+
 	rootCmd.AddCommand(runCmd)
 }
+
+// newPrinter returns the printer for the format set using the flags, along with
+// a function to close the report output file, if any.
+func newPrinter() (runner.IssuePrinter, func() error, error) {
+	var textPrinter runner.IssuePrinter
+	if pretty {
+		textPrinter = runner.NewPrettyIssuePrinter()
+	} else {
+		textPrinter = &runner.DefaultIssuePrinter{}
+	}
+
+	switch format {
+	case "text":
+		if outputFile != "" {
+			return nil, nil, errors.New("--output is not supported with the text format, which is printed on stdout")
+		}
+		return textPrinter, nil, nil
+	case "github":
+		if outputFile != "" {
+			return nil, nil, errors.New("--output is not supported with the github format, which is printed on stdout")
+		}
+		// The annotations are printed along with the text output, as GitHub Actions
+		// hides the workflow commands from the logs.
+		return runner.MultiIssuePrinter{
+			textPrinter,
+			runner.NewGitHubIssuePrinter(os.Stdout),
+		}, nil, nil
+	}
+
+	var out io.Writer = os.Stdout
+	closeOutput := func() error { return nil }
+	if outputFile != "" {
+		f, err := os.Create(outputFile)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		out = f
+		closeOutput = f.Close
+	}
+
+	var reportPrinter runner.IssuePrinter
+	switch format {
+	case "junit":
+		reportPrinter = runner.NewJUnitIssuePrinter(out)
+	case "json":
+		reportPrinter = runner.NewJSONIssuePrinter(out)
+	case "sarif":
+		reportPrinter = runner.NewSARIFIssuePrinter(out)
+	default:
+		_ = closeOutput()
+		return nil, nil, fmt.Errorf("unsupported format %q", format)
+	}
+
+	if outputFile == "" {
+		return reportPrinter, closeOutput, nil
+	}
+
+	// The report is written to a file, so the results are printed on stdout as
+	// well.
+	return runner.MultiIssuePrinter{textPrinter, reportPrinter}, closeOutput, nil
+}
+
+// flushPrinter writes the report if the printer is a runner.ReportPrinter and
+// closes the report output.
+func flushPrinter(printer runner.IssuePrinter, closeOutput func() error) error {
+	if closeOutput == nil {
+		return nil
+	}
+
+	if p, ok := printer.(runner.ReportPrinter); ok {
+		if err := p.Flush(); err != nil {
+			_ = closeOutput()
+			return err
+		}
+	}
+
+	return closeOutput()
+}
+
+// runSuites runs the suites matched by the patterns.
+func runSuites(ctx context.Context, printer runner.IssuePrinter, patterns []string) (bool, error) {
+	suites, err := runner.DiscoverSuites(patterns)
+	if err != nil {
+		return false, err
+	}
+
+	if len(suites) == 0 {
+		return false, errors.New("no suites found")
+	}
+
+	return runner.RunSuites(ctx, suites, jobs, runOptions(printer))
+}
+
+// runOptions returns the options of the runner using the flags.
+func runOptions(printer runner.IssuePrinter) runner.Options {
+	return runner.Options{
+		Files:      files,
+		AutofixDir: autofixDir,
+		Lint:       lint,
+		Timeout:    timeout,
+		Printers:   []runner.IssuePrinter{printer},
+	}
+}
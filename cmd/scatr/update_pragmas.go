@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/deepsourcelabs/SCATR/runner"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var dryRun bool
+
+var updatePragmasCmd = &cobra.Command{
+	Use:   "update-pragmas",
+	Short: "Update the pragmas in the files to match the analysis result",
+	Long: "Runs the checks and rewrites the pragmas in the files so that they match the " +
+		"analysis result. Missing pragmas are added, pragmas which were not raised are " +
+		"removed, and the columns and titles are updated. Other comments are kept as is.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := os.Chdir(runCwd)
+		if err != nil {
+			return err
+		}
+
+		var printer runner.IssuePrinter
+		if pretty {
+			printer = runner.NewPrettyIssuePrinter()
+		} else {
+			printer = &runner.DefaultIssuePrinter{}
+		}
+
+		if !verbose {
+			log.SetOutput(io.Discard)
+		}
+
+		ctx, stop := signalContext()
+		defer stop()
+
+		updated, err := runner.UpdatePragmas(ctx, printer, files, dryRun, timeout)
+		if err != nil {
+			fmt.Println(err)
+			// skipcq: RVV-A0003
+			os.Exit(exitCode(err))
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		if len(updated) == 0 {
+			fmt.Println("All pragmas are up to date")
+			return nil
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		for _, file := range updated {
+			if rel, err := filepath.Rel(cwd, file); err == nil {
+				file = rel
+			}
+
+			fmt.Println("Updated", file)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	updatePragmasCmd.Flags().StringVarP(
+		&runCwd, "cwd", "c", ".",
+		"Set the current working directory of the runner.",
+	)
+	updatePragmasCmd.Flags().BoolVarP(
+		&pretty, "pretty", "p", term.IsTerminal(int(os.Stdout.Fd())),
+		"Pretty print the diffs in dry-run mode",
+	)
+	updatePragmasCmd.Flags().BoolVarP(
+		&verbose, "verbose", "v", false,
+		"Use verbose logging",
+	)
+	updatePragmasCmd.Flags().StringArrayVarP(
+		&files, "files", "f", []string{},
+		"Set the list of files to update the pragmas in. This is relative to the specified cwd.",
+	)
+	updatePragmasCmd.Flags().BoolVar(
+		&dryRun, "dry-run", false,
+		"Print the diff of the pragma updates instead of writing the files",
+	)
+
+	rootCmd.AddCommand(updatePragmasCmd)
+}
@@ -0,0 +1,349 @@
+package pragma
+
+import "strings"
+
+// BlockComment is the start and the end of a block comment, like /* and */ or
+// <!-- and -->.
+type BlockComment struct {
+	Start string
+	End   string
+}
+
+// comment is a comment on a line of a file, which may contain a pragma.
+type comment struct {
+	line   int    // line of the comment, starting from 0
+	start  int    // byte offset of the comment in the line
+	end    int    // byte offset of the end of the comment in the line
+	prefix string // comment prefix, or the start of the block comment if it starts on the line
+	suffix string // end of the block comment if it ends on the line
+	text   string // text of the comment on the line, without the prefix and the suffix
+
+	// alone is whether the line has nothing before the comment.
+	alone bool
+
+	// block is the block comment the comment is a part of, or nil for the line
+	// comments.
+	block *blockSpan
+}
+
+// blockSpan is the lines of a block comment.
+type blockSpan struct {
+	first int // first line of the block comment, starting from 0
+	last  int // last line of the block comment, starting from 0
+
+	codeBefore bool // whether there is code before the block comment on its first line
+	codeAfter  bool // whether there is code after the block comment on its last line
+}
+
+// wholeLine reports whether the comment doesn't share its lines with code, in
+// which case its pragmas apply to the next line.
+func (c *comment) wholeLine() bool {
+	if c.block == nil {
+		return c.alone
+	}
+
+	return !c.block.codeBefore && !c.block.codeAfter
+}
+
+// firstLine returns the first line of the comment, starting from 0.
+func (c *comment) firstLine() int {
+	if c.block == nil {
+		return c.line
+	}
+
+	return c.block.first
+}
+
+// target returns the line the pragmas in the comment apply to, starting from
+// 0. The pragmas in a block comment on the lines of some code apply to the
+// line of the code, even if the block comment spans multiple lines.
+func (c *comment) target() int {
+	switch {
+	case c.wholeLine() && c.block == nil:
+		return c.line + 1
+	case c.wholeLine():
+		return c.block.last + 1
+	case c.block == nil:
+		return c.line
+	case c.block.codeBefore:
+		return c.block.first
+	default:
+		return c.block.last
+	}
+}
+
+// partial reports whether the comment is a part of a block comment spanning
+// multiple lines.
+func (c *comment) partial() bool {
+	return c.block != nil && c.block.first != c.block.last
+}
+
+// commentScanner finds the comments in the lines of a file. The block
+// comments and the literals which don't end on a line are kept open for the
+// next lines.
+type commentScanner struct {
+	prefixes []string
+	blocks   []BlockComment
+
+	// language is used to skip the literals, or nil to split the lines at the
+	// comment prefixes regardless of the literals.
+	language *Language
+
+	block   *BlockComment // block comment started on a previous line
+	span    *blockSpan    // lines of the block comment started on a previous line
+	literal *literal      // literal started on a previous line
+}
+
+func (f *File) commentScanner() *commentScanner {
+	return &commentScanner{prefixes: f.CommentPrefix, blocks: f.BlockComments, language: f.Language}
+}
+
+// scanComments returns the comments on each line of the file, in the order
+// they are to be checked for a pragma.
+func (f *File) scanComments(lines []string) [][]*comment {
+	s := f.commentScanner()
+
+	comments := make([][]*comment, len(lines))
+	for i, line := range lines {
+		comments[i] = s.scanLine(line, i)
+	}
+
+	return comments
+}
+
+// scanLine returns the comments on the line i, starting from 0. A block
+// comment lasts till its end, which may be on another line, and the block
+// comments are not nested.
+func (s *commentScanner) scanLine(line string, i int) []*comment {
+	var comments []*comment
+	offset := 0
+
+	switch {
+	case s.block != nil:
+		// The line is a part of a block comment started on a previous line.
+		start := len(line) - len(strings.TrimLeft(line, " \t"))
+		c := &comment{line: i, start: start, end: len(line), text: line[start:], alone: true, block: s.span}
+		comments = append(comments, c)
+
+		s.span.last = i
+		end := strings.Index(line, s.block.End)
+		if end == -1 {
+			return comments
+		}
+
+		c.end, c.suffix = end+len(s.block.End), s.block.End
+		c.text = line[start:end]
+
+		s.span.codeAfter = strings.TrimSpace(line[c.end:]) != ""
+		s.block, s.span = nil, nil
+		offset = c.end
+
+	case s.literal != nil:
+		// The line is a part of a literal started on a previous line.
+		end, ok := s.literal.endIn(line, 0)
+		if !ok {
+			return nil
+		}
+
+		s.literal = nil
+		offset = end
+	}
+
+	if s.language == nil {
+		return append(comments, s.splitComments(line, i, offset)...)
+	}
+
+	return append(comments, s.lexComments(line, i, offset)...)
+}
+
+// hasComment reports whether the line i has a comment, without changing the
+// state of the scanner.
+func (s commentScanner) hasComment(line string, i int) bool {
+	if s.span != nil {
+		span := *s.span
+		s.span = &span
+	}
+
+	return len(s.scanLine(line, i)) != 0
+}
+
+// splitComments returns the comments after the offset of the line, regardless
+// of the literals: the block comments in the order they start, followed by the
+// line comments in the order of the prefixes. The text of a line comment lasts
+// till the next occurrence of its prefix. The block comments starting after a
+// line comment are ignored, as are the line comments inside a block comment.
+func (s *commentScanner) splitComments(line string, i, offset int) []*comment {
+	var comments []*comment
+
+	// The block comments are only searched for before the first line comment,
+	// and the line comments after the block comments.
+	for {
+		limit := len(line)
+		for _, prefix := range s.prefixes {
+			if start := strings.Index(line[offset:], prefix); start != -1 && offset+start < limit {
+				limit = offset + start
+			}
+		}
+
+		block, start := firstBlockComment(line[offset:limit], s.blocks)
+		if block == nil {
+			break
+		}
+
+		c := s.blockComment(line, i, offset+start, block)
+		comments = append(comments, c)
+		if s.block != nil {
+			return comments
+		}
+
+		offset = c.end
+	}
+
+	for _, prefix := range s.prefixes {
+		split := strings.Split(line[offset:], prefix)
+		if len(split) < 2 {
+			continue
+		}
+
+		start := offset + len(split[0])
+		comments = append(comments, s.lineComment(line, i, start, prefix))
+	}
+
+	return comments
+}
+
+// lexComments returns the comments after the offset of the line which are not
+// inside a literal of the language, in the order they start. A line comment
+// lasts till the end of the line, but its text lasts till the next occurrence
+// of its prefix, just like with splitComments. The rest of the line is split
+// using splitComments if a single-line literal doesn't end on the line, as the
+// literal was likely not one, like a quote in a regular expression.
+func (s *commentScanner) lexComments(line string, i, offset int) []*comment {
+	var comments []*comment
+
+	for pos := offset; pos < len(line); {
+		if block := blockCommentAt(line[pos:], s.blocks); block != nil {
+			c := s.blockComment(line, i, pos, block)
+			comments = append(comments, c)
+			if s.block != nil {
+				break
+			}
+
+			pos = c.end
+			continue
+		}
+
+		if prefix := s.lineCommentAt(line, pos); prefix != "" {
+			comments = append(comments, s.lineComment(line, i, pos, prefix))
+			break
+		}
+
+		if lit := s.language.literalAt(line[pos:]); lit != nil {
+			end, ok := lit.endIn(line, pos+len(lit.start))
+			if !ok && !lit.multiLine {
+				return append(comments, s.splitComments(line, i, pos)...)
+			}
+			if !ok {
+				s.literal = lit
+			}
+
+			pos = end
+			continue
+		}
+
+		pos++
+	}
+
+	return comments
+}
+
+// lineCommentAt returns the prefix of the line comment starting at the offset
+// of the line, or an empty string if there is none.
+func (s *commentScanner) lineCommentAt(line string, offset int) string {
+	for _, prefix := range s.prefixes {
+		if prefix == "" || !strings.HasPrefix(line[offset:], prefix) {
+			continue
+		}
+
+		if s.language.spacedComments && offset > 0 && !strings.ContainsRune(" \t;&|()", rune(line[offset-1])) {
+			continue
+		}
+
+		return prefix
+	}
+
+	return ""
+}
+
+// lineComment returns the line comment with the prefix starting at the offset
+// of the line i.
+func (s *commentScanner) lineComment(line string, i, start int, prefix string) *comment {
+	text := line[start+len(prefix):]
+	if end := strings.Index(text, prefix); end != -1 {
+		text = text[:end]
+	}
+
+	return &comment{
+		line:   i,
+		start:  start,
+		end:    start + len(prefix) + len(text),
+		prefix: prefix,
+		text:   text,
+		alone:  strings.TrimSpace(line[:start]) == "",
+	}
+}
+
+// blockComment returns the block comment starting at the offset of the line
+// i. The block comment is kept open if it doesn't end on the line.
+func (s *commentScanner) blockComment(line string, i, start int, block *BlockComment) *comment {
+	textStart := start + len(block.Start)
+	span := &blockSpan{first: i, last: i, codeBefore: strings.TrimSpace(line[:start]) != ""}
+	c := &comment{
+		line:   i,
+		start:  start,
+		end:    len(line),
+		prefix: block.Start,
+		text:   line[textStart:],
+		alone:  !span.codeBefore,
+		block:  span,
+	}
+
+	end := strings.Index(line[textStart:], block.End)
+	if end == -1 {
+		s.block, s.span = block, span
+		return c
+	}
+
+	end += textStart
+	c.end, c.suffix, c.text = end+len(block.End), block.End, line[textStart:end]
+	span.codeAfter = strings.TrimSpace(line[c.end:]) != ""
+	return c
+}
+
+// firstBlockComment returns the block comment starting first in s, along with
+// its offset, or nil if there is none.
+func firstBlockComment(s string, blocks []BlockComment) (*BlockComment, int) {
+	var first *BlockComment
+	firstStart := -1
+
+	for i := range blocks {
+		start := strings.Index(s, blocks[i].Start)
+		if start != -1 && (firstStart == -1 || start < firstStart) {
+			first, firstStart = &blocks[i], start
+		}
+	}
+
+	return first, firstStart
+}
+
+// blockCommentAt returns the block comment starting at the start of s, or nil
+// if there is none.
+func blockCommentAt(s string, blocks []BlockComment) *BlockComment {
+	for i := range blocks {
+		if blocks[i].Start != "" && strings.HasPrefix(s, blocks[i].Start) {
+			return &blocks[i]
+		}
+	}
+
+	return nil
+}
@@ -36,15 +36,43 @@ type File struct {
 	CommentPrefix []string
 	Pragmas       map[int]*Pragma
 
+	// BlockComments is the block comments which can contain pragmas, in
+	// addition to the line comments using the CommentPrefix.
+	BlockComments []BlockComment
+
+	// Language is used to ignore the comment prefixes inside the string and
+	// character literals. The lines are split at the comment prefixes in case
+	// it is nil.
+	Language *Language
+
 	CheckMode  CheckMode
 	IssueCodes []string // issue codes to include / exclude based on the CheckMode.
+
+	// OutsideLines is the lines outside the file which the pragmas written with
+	// a line offset apply to, in the order the pragmas are written.
+	OutsideLines []int
+}
+
+// NewFile returns the file with its pragmas, using the language of its
+// extension, if any.
+func NewFile(name, content string, commentPrefix []string, blockComments ...BlockComment) *File {
+	return NewFileWithLanguage(name, content, LanguageForFile(name), commentPrefix, blockComments...)
 }
 
-func NewFile(name, content string, commentPrefix []string) *File {
+// NewFileWithLanguage returns the file with its pragmas, using the language
+// regardless of its extension.
+func NewFileWithLanguage(
+	name, content string,
+	language *Language,
+	commentPrefix []string,
+	blockComments ...BlockComment,
+) *File {
 	file := &File{
 		Name:          strings.TrimSuffix(name, filepath.Ext(name)),
 		Content:       content,
 		CommentPrefix: commentPrefix,
+		BlockComments: blockComments,
+		Language:      language,
 		Pragmas:       make(map[int]*Pragma),
 		CheckMode:     CheckAll,
 		IssueCodes:    nil,
@@ -104,12 +132,8 @@ func (f *File) readCheckMode(comment string) {
 func (f *File) extractPragmas() {
 	reader := bufio.NewReader(strings.NewReader(f.Content))
 
-	currentLineNum := 0
-	previousLine := ""
-	var previousPragmaWithCode *Pragma
+	var lines []string
 	for {
-		currentLineNum++
-
 		line, err := readLine(reader)
 		if err != nil {
 			if err != io.EOF {
@@ -118,27 +142,42 @@ func (f *File) extractPragmas() {
 			break
 		}
 
-		line = strings.TrimSpace(line)
-		previousLine = line
+		lines = append(lines, strings.TrimSpace(line))
+	}
+
+	var previousPragmaWithCode *Pragma
+
+	// The pragmas written with a line offset are added once all the lines are
+	// read, to not interfere with the pragmas applying to the next line.
+	type offsetPragma struct {
+		line   int // line of the comment
+		pragma *Pragma
+	}
+	var offsetPragmas []offsetPragma
+
+	for i, comments := range f.scanComments(lines) {
+		currentLineNum := i + 1
 
 		var pragma *Pragma
+		var c *comment
 		lineNum := currentLineNum
-		for _, prefix := range f.CommentPrefix {
-			split := strings.Split(line, prefix)
-			if len(split) < 2 {
-				continue
+		for _, c = range comments {
+			if currentLineNum == 1 {
+				f.readCheckMode(c.text)
 			}
 
-			if lineNum == 1 {
-				f.readCheckMode(split[1])
-			}
-
-			pragma = ParsePragma(split[1])
+			pragma = ParsePragma(c.text)
 			if pragma != nil {
-				if strings.HasPrefix(line, prefix) {
-					// If the line starts with a comment then the issue will be raised on
-					// the next line.
-					lineNum++
+				for _, o := range pragma.Offsets {
+					offsetPragmas = append(offsetPragmas, offsetPragma{line: currentLineNum, pragma: o})
+				}
+				pragma.Offsets = nil
+
+				// If the comment is on its own lines then the issue will be
+				// raised on the next line. The pragmas in a block comment
+				// apply to the same line regardless of the line they are on.
+				lineNum = c.target() + 1
+				if c.wholeLine() {
 					previousPragmaWithCode = pragma
 				}
 
@@ -158,10 +197,11 @@ func (f *File) extractPragmas() {
 		}
 
 		if pragma != nil {
-			// Check if we have a pragma on the previous line, and if we do, delete
-			// that and merge that with the current line's pragma
+			// Check if we have a pragma on the line before the comment, and if we
+			// do, delete that and merge that with the current line's pragma
 
-			previousPragma, ok := f.Pragmas[lineNum-1]
+			firstLineNum := c.firstLine() + 1
+			previousPragma, ok := f.Pragmas[firstLineNum]
 			if !ok {
 				continue
 			}
@@ -170,23 +210,54 @@ func (f *File) extractPragmas() {
 				continue
 			}
 
-			// Previous line also has code
-			if !hasPrefixes(previousLine, f.CommentPrefix) {
+			// The comment is on the lines of some code
+			if !c.wholeLine() {
 				continue
 			}
 
 			pragma.merge(previousPragma)
-			delete(f.Pragmas, lineNum-1)
+			delete(f.Pragmas, firstLineNum)
+		}
+	}
+
+	// The pragmas applying to a line outside the file are kept on that line,
+	// so that their issues are reported as not raised.
+	numLines := f.LineCount()
+	for _, o := range offsetPragmas {
+		target := o.pragma.Offset.Target(o.line)
+		o.pragma.Offset = nil
+		if target < 1 || target > numLines {
+			f.OutsideLines = append(f.OutsideLines, target)
+		}
+
+		if p, ok := f.Pragmas[target]; ok {
+			p.merge(o.pragma)
+		} else {
+			f.Pragmas[target] = o.pragma
 		}
 	}
-}
 
-func hasPrefixes(line string, prefixes []string) bool {
-	for _, prefix := range prefixes {
-		if strings.HasPrefix(line, prefix) {
-			return true
+	// A comment with only the pragmas written with line offsets doesn't
+	// expect any issue on its own line.
+	for line, p := range f.Pragmas {
+		if p.isEmpty() {
+			delete(f.Pragmas, line)
 		}
 	}
+}
+
+// LineCount returns the number of lines in the file.
+func (f *File) LineCount() int {
+	return lineCount(f.Content)
+}
+
+// lineCount returns the number of lines in the content, not counting the empty
+// line after the final newline.
+func lineCount(content string) int {
+	n := strings.Count(content, "\n")
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		n++
+	}
 
-	return false
+	return n
 }
@@ -11,6 +11,8 @@ func TestNewFile(t *testing.T) {
 	type args struct {
 		content       string
 		commentPrefix []string
+		blockComments []BlockComment
+		language      string
 	}
 	tests := []struct {
 		name string
@@ -394,10 +396,264 @@ issueCaseRaised(010);`,
 				},
 			},
 		},
+		{
+			name: "forbidden issue codes on multiple lines - go",
+			args: args{
+				content: `package main
+
+// [!GO-W1008]
+// [GO-W1000]: 5
+var foo = 10 // [!GO-W1009]`,
+				commentPrefix: []string{"//"},
+			},
+			want: map[int]*Pragma{
+				5: {
+					Issues:    map[string][]*Issue{"GO-W1000": {{Column: 5}}},
+					Hit:       map[string]bool{"GO-W1000": false},
+					Forbidden: map[string]bool{"GO-W1008": true, "GO-W1009": true},
+				},
+			},
+		},
+		{
+			name: "line offsets - go",
+			args: args{
+				content: "package main\n\nvar query = `\nSELECT *\nFROM foo\n`\n" +
+					"// [GO-W1000]@-2: 1; [GO-W1001]@L4\n" +
+					"// [GO-W1002]\n" +
+					"var bar = 10 // [GO-W1003]@+100\n",
+				commentPrefix: []string{"//"},
+			},
+			want: map[int]*Pragma{
+				4: {
+					Issues: map[string][]*Issue{"GO-W1001": {}},
+					Hit:    map[string]bool{"GO-W1001": false},
+				},
+				5: {
+					Issues: map[string][]*Issue{"GO-W1000": {{Column: 1}}},
+					Hit:    map[string]bool{"GO-W1000": false},
+				},
+				9: {
+					Issues: map[string][]*Issue{"GO-W1002": {}},
+					Hit:    map[string]bool{"GO-W1002": false},
+				},
+				109: {
+					Issues: map[string][]*Issue{"GO-W1003": {}},
+					Hit:    map[string]bool{"GO-W1003": false},
+				},
+			},
+		},
+		{
+			name: "line offsets outside the file - go",
+			args: args{
+				content:       "package main\n\n// [GO-W1000]@L0; [GO-W1001]@-5\nvar bar = 10\n",
+				commentPrefix: []string{"//"},
+			},
+			want: map[int]*Pragma{
+				-2: {
+					Issues: map[string][]*Issue{"GO-W1001": {}},
+					Hit:    map[string]bool{"GO-W1001": false},
+				},
+				0: {
+					Issues: map[string][]*Issue{"GO-W1000": {}},
+					Hit:    map[string]bool{"GO-W1000": false},
+				},
+			},
+		},
+		{
+			name: "block comments - css",
+			args: args{
+				content: `/* [CSS-W1000]: 3 */
+a { color: red; }
+b { color: blue; } /* [CSS-W1001] */
+/*
+  [CSS-W1002]: 5
+  [CSS-W1003]
+*/
+c { color: green; }
+d { /* [CSS-W1004] */ }
+e {
+  color: red; /* [CSS-W1005]
+  [CSS-W1006] */
+}`,
+				blockComments: []BlockComment{{Start: "/*", End: "*/"}},
+			},
+			want: map[int]*Pragma{
+				2: {
+					Issues: map[string][]*Issue{"CSS-W1000": {{Column: 3}}},
+					Hit:    map[string]bool{"CSS-W1000": false},
+				},
+				3: {
+					Issues: map[string][]*Issue{"CSS-W1001": {}},
+					Hit:    map[string]bool{"CSS-W1001": false},
+				},
+				8: {
+					Issues: map[string][]*Issue{"CSS-W1002": {{Column: 5}}, "CSS-W1003": {}},
+					Hit:    map[string]bool{"CSS-W1002": false, "CSS-W1003": false},
+				},
+				9: {
+					Issues: map[string][]*Issue{"CSS-W1004": {}},
+					Hit:    map[string]bool{"CSS-W1004": false},
+				},
+				11: {
+					Issues: map[string][]*Issue{"CSS-W1005": {}, "CSS-W1006": {}},
+					Hit:    map[string]bool{"CSS-W1005": false, "CSS-W1006": false},
+				},
+			},
+		},
+		{
+			name: "block comments - html",
+			args: args{
+				content: `<!-- [HTML-W1000] -->
+<img src="a.png">
+<p>Hello</p> <!-- [HTML-W1001]: 1 -->`,
+				blockComments: []BlockComment{{Start: "<!--", End: "-->"}},
+			},
+			want: map[int]*Pragma{
+				2: {
+					Issues: map[string][]*Issue{"HTML-W1000": {}},
+					Hit:    map[string]bool{"HTML-W1000": false},
+				},
+				3: {
+					Issues: map[string][]*Issue{"HTML-W1001": {{Column: 1}}},
+					Hit:    map[string]bool{"HTML-W1001": false},
+				},
+			},
+		},
+		{
+			name: "line and block comments - go",
+			args: args{
+				content: `package main
+
+// [GO-W1000]
+/* [GO-W1001]
+   [GO-W1002] */
+var foo = 10 // [GO-W1003]
+
+/* a comment // [GO-W1005] */
+var bar = 10`,
+				commentPrefix: []string{"//"},
+				blockComments: []BlockComment{{Start: "/*", End: "*/"}},
+			},
+			want: map[int]*Pragma{
+				6: {
+					Issues: map[string][]*Issue{"GO-W1000": {}, "GO-W1001": {}, "GO-W1002": {}, "GO-W1003": {}},
+					Hit:    map[string]bool{"GO-W1000": false, "GO-W1001": false, "GO-W1002": false, "GO-W1003": false},
+				},
+				9: {
+					Issues: map[string][]*Issue{"GO-W1005": {}},
+					Hit:    map[string]bool{"GO-W1005": false},
+				},
+			},
+		},
+		{
+			name: "string literals - go",
+			args: args{
+				content: `package main
+
+var url = "http://example.com" // [GO-W1000]
+var raw = ` + "`" + `
+// [GO-W1001]
+` + "`" + ` // [GO-W1002]
+var quote = '"' // [GO-W1003]
+var escaped = "\"//" // [GO-W1004]`,
+				commentPrefix: []string{"//"},
+				language:      "go",
+			},
+			want: map[int]*Pragma{
+				3: {
+					Issues: map[string][]*Issue{"GO-W1000": {}},
+					Hit:    map[string]bool{"GO-W1000": false},
+				},
+				6: {
+					Issues: map[string][]*Issue{"GO-W1002": {}},
+					Hit:    map[string]bool{"GO-W1002": false},
+				},
+				7: {
+					Issues: map[string][]*Issue{"GO-W1003": {}},
+					Hit:    map[string]bool{"GO-W1003": false},
+				},
+				8: {
+					Issues: map[string][]*Issue{"GO-W1004": {}},
+					Hit:    map[string]bool{"GO-W1004": false},
+				},
+			},
+		},
+		{
+			name: "string literals - python",
+			args: args{
+				content: `s = "# [PY-W1000]"
+doc = """
+# [PY-W1001]
+"""  # [PY-W1002]
+t = 'it\'s #'  # [PY-W1003]`,
+				commentPrefix: []string{"#"},
+				language:      "python",
+			},
+			want: map[int]*Pragma{
+				4: {
+					Issues: map[string][]*Issue{"PY-W1002": {}},
+					Hit:    map[string]bool{"PY-W1002": false},
+				},
+				5: {
+					Issues: map[string][]*Issue{"PY-W1003": {}},
+					Hit:    map[string]bool{"PY-W1003": false},
+				},
+			},
+		},
+		{
+			name: "string literals - shell",
+			args: args{
+				content: `echo "# [SH-W1000]" $# ${#args[@]} # [SH-W1001]
+echo 'C:\' # [SH-W1002]
+echo it\'s # [SH-W1003]`,
+				commentPrefix: []string{"#"},
+				language:      "shell",
+			},
+			want: map[int]*Pragma{
+				1: {
+					Issues: map[string][]*Issue{"SH-W1001": {}},
+					Hit:    map[string]bool{"SH-W1001": false},
+				},
+				2: {
+					Issues: map[string][]*Issue{"SH-W1002": {}},
+					Hit:    map[string]bool{"SH-W1002": false},
+				},
+				3: {
+					Issues: map[string][]*Issue{"SH-W1003": {}},
+					Hit:    map[string]bool{"SH-W1003": false},
+				},
+			},
+		},
+		{
+			name: "string literals - javascript",
+			args: args{
+				content: `const url = "http://example.com"; // [JS-W1000]
+const re = /"/; // [JS-W1001]`,
+				commentPrefix: []string{"//"},
+				language:      "javascript",
+			},
+			want: map[int]*Pragma{
+				1: {
+					Issues: map[string][]*Issue{"JS-W1000": {}},
+					Hit:    map[string]bool{"JS-W1000": false},
+				},
+				2: {
+					Issues: map[string][]*Issue{"JS-W1001": {}},
+					Hit:    map[string]bool{"JS-W1001": false},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewFile("", tt.args.content, tt.args.commentPrefix); !reflect.DeepEqual(got.Pragmas, tt.want) {
+			got := NewFileWithLanguage(
+				"",
+				tt.args.content,
+				LanguageByName(tt.args.language),
+				tt.args.commentPrefix,
+				tt.args.blockComments...,
+			)
+			if !reflect.DeepEqual(got.Pragmas, tt.want) {
 				t.Errorf("NewFile() = %v, want %v, diff %v", got.Pragmas, tt.want,
 					cmp.Diff(tt.want, got.Pragmas))
 			}
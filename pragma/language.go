@@ -0,0 +1,138 @@
+package pragma
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Language is the syntax of the string and character literals of a language,
+// used to only find the comments which are not inside a literal.
+type Language struct {
+	// literals is the literals of the language, with the longer starts first.
+	literals []literal
+
+	// spacedComments is whether a line comment only starts at the start of a
+	// word, like # in the shell.
+	spacedComments bool
+}
+
+// literal is a string or a character literal.
+type literal struct {
+	start string
+	end   string
+
+	escapes   bool // whether a backslash escapes the next character
+	multiLine bool // whether the literal can span multiple lines
+}
+
+var (
+	doubleQuoted = literal{start: `"`, end: `"`, escapes: true}
+	singleQuoted = literal{start: `'`, end: `'`, escapes: true}
+
+	cFamily    = &Language{literals: []literal{doubleQuoted, singleQuoted}}
+	javaScript = &Language{literals: []literal{
+		{start: "`", end: "`", escapes: true, multiLine: true},
+		doubleQuoted,
+		singleQuoted,
+	}}
+)
+
+// languages is the languages by their name.
+var languages = map[string]*Language{
+	"go": {literals: []literal{
+		{start: "`", end: "`", multiLine: true},
+		doubleQuoted,
+		singleQuoted,
+	}},
+	"python": {literals: []literal{
+		{start: `"""`, end: `"""`, escapes: true, multiLine: true},
+		{start: `'''`, end: `'''`, escapes: true, multiLine: true},
+		doubleQuoted,
+		singleQuoted,
+	}},
+	"javascript": javaScript,
+	"typescript": javaScript,
+	"java": {literals: []literal{
+		{start: `"""`, end: `"""`, escapes: true, multiLine: true},
+		doubleQuoted,
+		singleQuoted,
+	}},
+	"c":      cFamily,
+	"cpp":    cFamily,
+	"csharp": cFamily,
+	"ruby":   {literals: []literal{doubleQuoted, singleQuoted}},
+	"shell": {
+		literals:       []literal{doubleQuoted, {start: `'`, end: `'`}},
+		spacedComments: true,
+	},
+}
+
+// languageExtensions is the names of the languages by the file extension.
+var languageExtensions = map[string]string{
+	".go":   "go",
+	".py":   "python",
+	".pyi":  "python",
+	".js":   "javascript",
+	".jsx":  "javascript",
+	".mjs":  "javascript",
+	".cjs":  "javascript",
+	".ts":   "typescript",
+	".tsx":  "typescript",
+	".mts":  "typescript",
+	".cts":  "typescript",
+	".java": "java",
+	".c":    "c",
+	".h":    "c",
+	".cc":   "cpp",
+	".cpp":  "cpp",
+	".cxx":  "cpp",
+	".hh":   "cpp",
+	".hpp":  "cpp",
+	".hxx":  "cpp",
+	".cs":   "csharp",
+	".rb":   "ruby",
+	".sh":   "shell",
+	".bash": "shell",
+	".zsh":  "shell",
+}
+
+// LanguageByName returns the language with the name, like "go" or "python",
+// or nil if it is not known.
+func LanguageByName(name string) *Language {
+	return languages[strings.ToLower(name)]
+}
+
+// LanguageForFile returns the language of the file based on its extension, or
+// nil if it is not known.
+func LanguageForFile(name string) *Language {
+	return languages[languageExtensions[strings.ToLower(filepath.Ext(name))]]
+}
+
+// literalAt returns the literal starting at the start of s, or nil if there is
+// none.
+func (l *Language) literalAt(s string) *literal {
+	for i := range l.literals {
+		if strings.HasPrefix(s, l.literals[i].start) {
+			return &l.literals[i]
+		}
+	}
+
+	return nil
+}
+
+// endIn returns the offset in the line after the end of the literal, searching
+// from the offset, and false if the literal doesn't end on the line.
+func (l *literal) endIn(line string, offset int) (int, bool) {
+	for i := offset; i < len(line); i++ {
+		if l.escapes && line[i] == '\\' {
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(line[i:], l.end) {
+			return i + len(l.end), true
+		}
+	}
+
+	return len(line), false
+}
@@ -0,0 +1,264 @@
+package pragma
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LintFinding is a comment which looks like a pragma or a directive, but is not
+// honoured as written.
+type LintFinding struct {
+	Line   int // line of the comment, starting from 1
+	Reason string
+}
+
+// Lint reports the comments in the file which look like a pragma but are not
+// fully parsed, the pragmas whose line offset applies to a line outside the
+// file, and the scatr-check / scatr-ignore directives which are not on the
+// first line, and hence are never honoured.
+func (f *File) Lint() []*LintFinding {
+	var findings []*LintFinding
+	numLines := lineCount(f.Content)
+
+	lines := strings.Split(f.Content, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	// The comments are read just like the pragma extraction does.
+	for i, comments := range f.scanComments(lines) {
+		lineNum := i + 1
+
+		for _, c := range comments {
+			if reasons, ok := lintDirective(c.text, lineNum); ok {
+				for _, reason := range reasons {
+					findings = append(findings, &LintFinding{Line: lineNum, Reason: reason})
+				}
+				break
+			}
+
+			if !looksLikePragma(c.text) {
+				continue
+			}
+
+			reasons := append(lintPragma(c.text), lintOffsets(c.text, lineNum, numLines)...)
+			for _, reason := range reasons {
+				findings = append(findings, &LintFinding{Line: lineNum, Reason: reason})
+			}
+			break
+		}
+	}
+
+	return findings
+}
+
+// lintDirective returns the reasons the comment is not honoured if it is a
+// scatr-check / scatr-ignore directive, and whether it is a directive.
+func lintDirective(comment string, lineNum int) ([]string, bool) {
+	comment = strings.TrimSpace(comment)
+
+	var directive string
+	for _, d := range []string{"scatr-check:", "scatr-ignore:"} {
+		if strings.HasPrefix(comment, d) {
+			directive = d
+			break
+		}
+	}
+
+	if directive == "" {
+		return nil, false
+	}
+
+	if lineNum != 1 {
+		return []string{fmt.Sprintf("%q directive is only honoured on line 1", directive)}, true
+	}
+
+	for _, code := range strings.Split(strings.TrimPrefix(comment, directive), ",") {
+		if strings.TrimSpace(code) == "" {
+			return []string{fmt.Sprintf("empty issue code in %q directive", directive)}, true
+		}
+	}
+
+	return nil, true
+}
+
+// looksLikePragma reports whether the comment is parsed as a pragma, or is
+// meant to be one.
+func looksLikePragma(comment string) bool {
+	return isPragma(comment) || strings.HasPrefix(strings.TrimSpace(comment), "[")
+}
+
+// lintPragma returns the reasons the pragma comment is not fully parsed by
+// ParsePragma.
+func lintPragma(comment string) []string {
+	var reasons []string
+
+	for _, segment := range splitWithEscaping(comment, ";", "\\") {
+		if strings.TrimSpace(segment) == "" {
+			reasons = append(reasons, "empty pragma, check for a stray ';'")
+			continue
+		}
+
+		match := pragmaRegex.FindStringSubmatchIndex(segment)
+		if match == nil {
+			reasons = append(reasons, fmt.Sprintf(
+				"%q is not a pragma, expected [ISSUE-CODE]", strings.TrimSpace(segment),
+			))
+			continue
+		}
+
+		issueCode := segment[match[2]:match[3]]
+
+		if before := strings.TrimSpace(segment[:match[0]]); before != "" {
+			reasons = append(reasons, fmt.Sprintf(
+				"unexpected text %q before [%s]", before, issueCode,
+			))
+		}
+
+		if match[4] != -1 {
+			if _, err := parseOffset(segment[match[4]:match[5]]); err != nil {
+				reasons = append(reasons, fmt.Sprintf(
+					"invalid line offset %q after [%s]", segment[match[4]:match[5]], issueCode,
+				))
+			}
+		}
+
+		if strings.HasPrefix(issueCode, "!") {
+			if match[6] != -1 {
+				reasons = append(reasons, fmt.Sprintf(
+					"forbidden issue code [%s] does not take an occurrence count", issueCode,
+				))
+			}
+
+			if match[10] != -1 || strings.TrimSpace(segment[match[1]:]) != "" {
+				reasons = append(reasons, fmt.Sprintf(
+					"forbidden issue code [%s] does not take columns or messages", issueCode,
+				))
+			}
+			continue
+		}
+
+		if match[6] != -1 {
+			if _, err := strconv.Atoi(segment[match[6]:match[7]]); err != nil {
+				reasons = append(reasons, fmt.Sprintf(
+					"invalid occurrence count %q after [%s]", segment[match[6]:match[7]], issueCode,
+				))
+			}
+		}
+
+		// The column / message pairs are absent.
+		if match[10] == -1 {
+			if after := strings.TrimSpace(segment[match[1]:]); after != "" {
+				reasons = append(reasons, fmt.Sprintf(
+					"unexpected text %q after [%s], expected ':'", after, issueCode,
+				))
+			}
+			continue
+		}
+
+		issues := segment[match[12]:match[13]]
+		if strings.TrimSpace(issues) == "" {
+			reasons = append(reasons, fmt.Sprintf(
+				"missing columns or messages after [%s]:", issueCode,
+			))
+			continue
+		}
+
+		if reason := lintIssues(issues); reason != "" {
+			reasons = append(reasons, fmt.Sprintf("[%s]: %s", issueCode, reason))
+		}
+	}
+
+	return reasons
+}
+
+// lintOffsets returns the reasons the pragmas with a line offset in the comment
+// on the line are ignored, as they apply to a line outside the file.
+func lintOffsets(comment string, lineNum, lines int) []string {
+	p := ParsePragma(comment)
+	if p == nil {
+		return nil
+	}
+
+	var reasons []string
+	for _, o := range p.Offsets {
+		if target := o.Offset.Target(lineNum); target < 1 || target > lines {
+			reasons = append(reasons, fmt.Sprintf(
+				"line offset %s applies to line %d, outside the file of %d lines",
+				o.Offset, target, lines,
+			))
+		}
+	}
+
+	return reasons
+}
+
+// lintIssues returns the reason the column / message pairs of a pragma are not
+// fully parsed, or an empty string if they are. The parsing stops at the first
+// malformed pair, so only that is reported.
+func lintIssues(issues string) string {
+	rest := strings.TrimSpace(issues)
+
+	for rest != "" {
+		if rest[0] == ',' {
+			return "empty column or message, check for a stray ','"
+		}
+
+		if _, quoted := splitMessageType(rest); quoted[0] != '"' {
+			end := strings.IndexAny(rest, " \t,\"")
+			if end == -1 {
+				end = len(rest)
+			}
+
+			column := rest[:end]
+			start, e, err := parsePosition(column)
+			if err != nil {
+				return fmt.Sprintf("invalid column %q", column)
+			}
+
+			if e != nil && e.Lines == 0 && e.Column < start {
+				return fmt.Sprintf("end column before the start column in %q", column)
+			}
+
+			rest = strings.TrimSpace(rest[end:])
+		}
+
+		if typ, quoted := splitMessageType(rest); strings.HasPrefix(quoted, `"`) {
+			end := closingQuote(quoted)
+			if end == -1 {
+				return fmt.Sprintf("unterminated message %s", rest)
+			}
+
+			message := rest[:len(rest)-len(quoted)+end+1]
+			if _, _, _, err := parseMessage(message); err != nil {
+				if typ == MessageExact {
+					return fmt.Sprintf("invalid message %s", message)
+				}
+				return fmt.Sprintf("invalid pattern %s: %v", message, err)
+			}
+
+			rest = strings.TrimSpace(quoted[end+1:])
+		}
+
+		// The pairs are separated using commas, which can be omitted.
+		if strings.HasPrefix(rest, ",") {
+			rest = strings.TrimSpace(rest[1:])
+		}
+	}
+
+	return ""
+}
+
+// closingQuote returns the index of the quote closing the message at the start
+// of s, or -1 if the message is unterminated. Just like the pragma parsing, an
+// escaped quote does not close the message.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '"' && s[i-1] != '\\' {
+			return i
+		}
+	}
+
+	return -1
+}
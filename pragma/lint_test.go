@@ -0,0 +1,134 @@
+package pragma
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFile_Lint(t *testing.T) {
+	type args struct {
+		content       string
+		commentPrefix []string
+		blockComments []BlockComment
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want []*LintFinding
+	}{
+		{
+			name: "valid pragmas",
+			args: args{
+				content: `// scatr-check: GO-W1000, GO-W1001
+package main
+
+// [GO-W1000]: 1 "Hello"; [GO-W1001] x2; [!GO-W1002]; [GO-W1003] x1+: 5
+var foo = 10 // [GO-W1000]: 1, 2 "World" 3, 5-12, 5:+2:8 "Span", re"\d+", 4 glob"a*"
+
+func main() {} // a comment
+var bar = 10 // [GO-W1004]@-1; [GO-W1005]@L1`,
+				commentPrefix: []string{"//"},
+			},
+			want: nil,
+		},
+		{
+			name: "malformed pragmas",
+			args: args{
+				content: `a = a  # [PYL-W0127]: 1 "Unterminated
+b = b  # [PYL-W0127]: x "Column"
+c = c  # [PYL-W0127]:
+d = d  # [PYL-W0127] 1 "Missing colon"
+e = e  # [PYL-W0127];
+f = f  # [PYL-W0127]: 1,, 2
+g = g  # [PYL-W0127]: 1 "Message" extra
+h = h  # see [PYL-W0127]
+i = i  # [PYL W0127]
+j = j  # [PYL-W0127]: 5-x
+k = k  # [PYL-W0127]: 5-3 "Backwards"
+l = l  # [PYL-W0127]: re"(unclosed"
+m = m  # [!PYL-W0127]: 5
+n = n  # [!PYL-W0127] x2
+o = o  # [PYL-W0127] x2 "Missing colon"
+p = p  # [PYL-W0127] x99999999999999999999
+q = q  # [PYL-W0127]@+2; [PYL-W0128]@-17; [PYL-W0129]@L0
+r = r  # [PYL-W0127]@L99999999999999999999`,
+				commentPrefix: []string{"#"},
+			},
+			want: []*LintFinding{
+				{Line: 1, Reason: `[PYL-W0127]: unterminated message "Unterminated`},
+				{Line: 2, Reason: `[PYL-W0127]: invalid column "x"`},
+				{Line: 3, Reason: `missing columns or messages after [PYL-W0127]:`},
+				{Line: 4, Reason: `unexpected text "1 \"Missing colon\"" after [PYL-W0127], expected ':'`},
+				{Line: 5, Reason: `empty pragma, check for a stray ';'`},
+				{Line: 6, Reason: `[PYL-W0127]: empty column or message, check for a stray ','`},
+				{Line: 7, Reason: `[PYL-W0127]: invalid column "extra"`},
+				{Line: 8, Reason: `unexpected text "see" before [PYL-W0127]`},
+				{Line: 9, Reason: `"[PYL W0127]" is not a pragma, expected [ISSUE-CODE]`},
+				{Line: 10, Reason: `[PYL-W0127]: invalid column "5-x"`},
+				{Line: 11, Reason: `[PYL-W0127]: end column before the start column in "5-3"`},
+				{Line: 12, Reason: "[PYL-W0127]: invalid pattern re\"(unclosed\": error parsing regexp: missing closing ): `(unclosed`"},
+				{Line: 13, Reason: `forbidden issue code [!PYL-W0127] does not take columns or messages`},
+				{Line: 14, Reason: `forbidden issue code [!PYL-W0127] does not take an occurrence count`},
+				{Line: 15, Reason: `unexpected text "\"Missing colon\"" after [PYL-W0127], expected ':'`},
+				{Line: 16, Reason: `invalid occurrence count "99999999999999999999" after [PYL-W0127]`},
+				{Line: 17, Reason: `line offset @+2 applies to line 19, outside the file of 18 lines`},
+				{Line: 17, Reason: `line offset @-17 applies to line 0, outside the file of 18 lines`},
+				{Line: 17, Reason: `line offset @L0 applies to line 0, outside the file of 18 lines`},
+				{Line: 18, Reason: `invalid line offset "L99999999999999999999" after [PYL-W0127]`},
+			},
+		},
+		{
+			name: "block comments",
+			args: args{
+				content: `<!-- scatr-check: HTML-W1000 -->
+<img src="a.png"> <!-- [HTML-W1000]: x -->
+<!--
+  [HTML-W1000]: 1 "Unterminated
+  a comment
+-->
+<p>Hello</p>`,
+				blockComments: []BlockComment{{Start: "<!--", End: "-->"}},
+			},
+			want: []*LintFinding{
+				{Line: 2, Reason: `[HTML-W1000]: invalid column "x"`},
+				{Line: 4, Reason: `[HTML-W1000]: unterminated message "Unterminated`},
+			},
+		},
+		{
+			name: "directives not on the first line",
+			args: args{
+				content: `package main
+
+// scatr-check: GO-W1000
+// scatr-ignore: GO-W1001
+func main() {}`,
+				commentPrefix: []string{"//"},
+			},
+			want: []*LintFinding{
+				{Line: 3, Reason: `"scatr-check:" directive is only honoured on line 1`},
+				{Line: 4, Reason: `"scatr-ignore:" directive is only honoured on line 1`},
+			},
+		},
+		{
+			name: "directive without issue codes",
+			args: args{
+				content:       `# scatr-ignore: PYL-W0127,`,
+				commentPrefix: []string{"#"},
+			},
+			want: []*LintFinding{
+				{Line: 1, Reason: `empty issue code in "scatr-ignore:" directive`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFile("", tt.args.content, tt.args.commentPrefix, tt.args.blockComments...)
+			if got := f.Lint(); !cmp.Equal(got, tt.want) {
+				t.Fatalf("Lint() diff: %s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
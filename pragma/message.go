@@ -0,0 +1,134 @@
+package pragma
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MessageType is how the message of a pragma issue is matched with the title
+// of a raised issue.
+type MessageType int
+
+const (
+	// MessageExact matches the titles equal to the message, like "title".
+	MessageExact MessageType = iota
+
+	// MessageRegex matches the titles containing a match of the regular
+	// expression in the message, like re"complexity \d+".
+	MessageRegex
+
+	// MessageGlob matches the titles matching the glob pattern in the message
+	// as a whole, like glob"function * is unused". A * matches any text, and a
+	// ? matches a single character.
+	MessageGlob
+)
+
+// messagePrefixes are the prefixes of the quoted messages which are patterns.
+var messagePrefixes = map[string]MessageType{
+	"re":   MessageRegex,
+	"glob": MessageGlob,
+}
+
+// splitMessageType returns the type of the quoted message at the start of s,
+// and s without the prefix of the type.
+func splitMessageType(s string) (MessageType, string) {
+	for prefix, typ := range messagePrefixes {
+		if strings.HasPrefix(s, prefix+`"`) {
+			return typ, s[len(prefix):]
+		}
+	}
+
+	return MessageExact, s
+}
+
+// parseMessage parses a quoted message of a pragma, along with its type
+// prefix, and compiles it in case it is a pattern. Unlike the exact messages,
+// the patterns are not unquoted, so that the escapes like \d are kept as is.
+func parseMessage(quoted string) (string, MessageType, *regexp.Regexp, error) {
+	typ, quoted := splitMessageType(quoted)
+	if typ == MessageExact {
+		message, err := strconv.Unquote(quoted)
+		return message, typ, nil, err
+	}
+
+	pattern := quoted[1 : len(quoted)-1]
+	re, err := compileMessage(pattern, typ)
+	if err != nil {
+		return "", typ, nil, err
+	}
+
+	return pattern, typ, re, nil
+}
+
+// compileMessage compiles the pattern of a message to a regular expression.
+func compileMessage(pattern string, typ MessageType) (*regexp.Regexp, error) {
+	if typ == MessageGlob {
+		return regexp.Compile(globRegexp(pattern))
+	}
+
+	return regexp.Compile(pattern)
+}
+
+// globRegexp returns the regular expression matching the same text as the glob
+// pattern. A backslash escapes the next character.
+func globRegexp(glob string) string {
+	var b strings.Builder
+	b.WriteString(`(?s)^`)
+
+	escaped := false
+	for _, r := range glob {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '*':
+			b.WriteString(`.*`)
+		case r == '?':
+			b.WriteString(`.`)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	if escaped {
+		b.WriteString(`\\`)
+	}
+
+	b.WriteString(`$`)
+	return b.String()
+}
+
+// MatchesTitle reports whether the title of a raised issue matches the message
+// of the issue. Any title matches an empty message. The pattern compiled while
+// parsing the pragma is used, and the message is only compiled for the issues
+// created without one.
+func (i *Issue) MatchesTitle(title string) bool {
+	if i.MessageType == MessageExact {
+		return i.Message == "" || i.Message == title
+	}
+
+	re := i.Pattern
+	if re == nil {
+		var err error
+		if re, err = compileMessage(i.Message, i.MessageType); err != nil {
+			return false
+		}
+	}
+
+	return re.MatchString(title)
+}
+
+// QuotedMessage returns the message of the issue using the pragma syntax, like
+// "title" or re"pattern".
+func (i *Issue) QuotedMessage() string {
+	for prefix, typ := range messagePrefixes {
+		if typ == i.MessageType {
+			return prefix + `"` + i.Message + `"`
+		}
+	}
+
+	return strconv.Quote(i.Message)
+}
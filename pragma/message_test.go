@@ -0,0 +1,75 @@
+package pragma
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestIssue_MatchesTitle(t *testing.T) {
+	tests := []struct {
+		name  string
+		issue *Issue
+		title string
+		want  bool
+	}{
+		{name: "empty message", issue: &Issue{}, title: "anything", want: true},
+		{name: "exact", issue: &Issue{Message: "foo"}, title: "foo", want: true},
+		{name: "exact mismatch", issue: &Issue{Message: "foo"}, title: "foo bar", want: false},
+		{
+			name:  "regex",
+			issue: &Issue{Message: `complexity \d+`, MessageType: MessageRegex},
+			title: "function foo has cyclomatic complexity 17",
+			want:  true,
+		},
+		{
+			name:  "regex mismatch",
+			issue: &Issue{Message: `^complexity \d+`, MessageType: MessageRegex},
+			title: "function foo has cyclomatic complexity 17",
+			want:  false,
+		},
+		{
+			name:  "glob",
+			issue: &Issue{Message: "function * has cyclomatic complexity ??", MessageType: MessageGlob},
+			title: "function foo/bar has cyclomatic complexity 17",
+			want:  true,
+		},
+		{
+			name:  "glob matches the whole title",
+			issue: &Issue{Message: "function *", MessageType: MessageGlob},
+			title: "the function foo is unused",
+			want:  false,
+		},
+		{
+			name:  "glob escaping",
+			issue: &Issue{Message: `is it \*?`, MessageType: MessageGlob},
+			title: "is it *?",
+			want:  true,
+		},
+		{
+			name:  "glob escaping mismatch",
+			issue: &Issue{Message: `is it \*?`, MessageType: MessageGlob},
+			title: "is it foo?",
+			want:  false,
+		},
+		{
+			name:  "compiled pattern",
+			issue: &Issue{Message: `^bar`, MessageType: MessageRegex, Pattern: regexp.MustCompile(`^foo`)},
+			title: "foo bar",
+			want:  true,
+		},
+		{
+			name:  "invalid regex",
+			issue: &Issue{Message: `(`, MessageType: MessageRegex},
+			title: "(",
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.issue.MatchesTitle(tt.title); got != tt.want {
+				t.Fatalf("MatchesTitle(%q) = %v, want %v", tt.title, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package pragma
 
 import (
+	"errors"
 	"regexp"
 	"strconv"
 	"strings"
@@ -12,14 +13,119 @@ type Issue struct {
 	Column  int
 	Message string
 
+	// MessageType is how the message is matched with the titles, which is
+	// exact unless the message is a pattern.
+	MessageType MessageType
+
+	// Pattern is the compiled pattern of the message, or nil for the exact
+	// messages.
+	Pattern *regexp.Regexp
+
+	// End is the expected end of the issue, or nil if it is not checked.
+	End *End
+
 	// Hit is used internally by the diff tool to check for issues that were not hit.
 	Hit bool
 }
 
+// End is the end position of an issue expected by a pragma, written as 5-12
+// for the columns 5 to 12 of the line, or 5:+2:8 for column 5 to column 8 of
+// the line two lines below.
+type End struct {
+	Lines  int // lines between the start and the end of the issue
+	Column int // end column, 0 if it is not checked
+}
+
 // Pragma is of the form [IssueCode]: Column "Message", Column "Message"; [AnotherIssueCode]: ...
 type Pragma struct {
 	Issues map[string][]*Issue
 	Hit    map[string]bool
+
+	// Forbidden is the issue codes which must not be raised, written as
+	// [!IssueCode]. It is nil if there are none.
+	Forbidden map[string]bool
+
+	// Counts is the number of times the issue codes are expected to be raised,
+	// written as [IssueCode] x3. It is nil if there are none.
+	Counts map[string]*Count
+
+	// Offset is the line offset the pragma is written with, or nil if it
+	// applies to the line of the comment (or the next line for a comment on its
+	// own line).
+	Offset *Offset
+
+	// Offsets is the pragmas in the same comment which are written with a line
+	// offset, one for each offset. The pragmas in File.Pragmas don't have any,
+	// as they are moved to the lines they apply to.
+	Offsets []*Pragma
+}
+
+// Offset is the line a pragma applies to, written after the issue code as
+// [IssueCode]@+3 or [IssueCode]@-2 for a line relative to the line of the
+// comment, or [IssueCode]@L42 for line 42.
+type Offset struct {
+	Line     int
+	Absolute bool
+}
+
+// Target returns the line the pragma applies to, given the line of its
+// comment.
+func (o *Offset) Target(commentLine int) int {
+	if o.Absolute {
+		return o.Line
+	}
+
+	return commentLine + o.Line
+}
+
+// parseOffset parses a line offset of a pragma, without the @.
+func parseOffset(s string) (*Offset, error) {
+	if strings.HasPrefix(s, "L") {
+		line, err := strconv.Atoi(s[1:])
+		return &Offset{Line: line, Absolute: true}, err
+	}
+
+	line, err := strconv.Atoi(s)
+	return &Offset{Line: line}, err
+}
+
+// offsetPragma returns the pragma of the comment written with the offset,
+// adding it if there is none yet.
+func (p *Pragma) offsetPragma(offset *Offset) *Pragma {
+	for _, o := range p.Offsets {
+		if *o.Offset == *offset {
+			return o
+		}
+	}
+
+	o := &Pragma{
+		Issues: make(map[string][]*Issue),
+		Hit:    make(map[string]bool),
+		Offset: offset,
+	}
+	p.Offsets = append(p.Offsets, o)
+	return o
+}
+
+// isEmpty reports whether the pragma doesn't expect or forbid any issue.
+func (p *Pragma) isEmpty() bool {
+	return len(p.Issues) == 0 && len(p.Forbidden) == 0
+}
+
+// Count is the number of occurrences of an issue expected by a pragma, written
+// as x3 for exactly 3 occurrences, or x3+ for at least 3.
+type Count struct {
+	N       int
+	AtLeast bool
+}
+
+// Matches reports whether the number of raised occurrences matches the count.
+func (c *Count) Matches(raised int) bool {
+	if c.AtLeast {
+		return raised >= c.N
+	}
+
+	return raised == c.N
 }
 
 // merge merges another pragma into the same pragma.
@@ -33,11 +139,41 @@ func (p *Pragma) merge(other *Pragma) {
 
 		p.Hit[issueCode] = p.Hit[issueCode] || other.Hit[issueCode]
 	}
+
+	for issueCode := range other.Forbidden {
+		p.forbid(issueCode)
+	}
+
+	for issueCode, count := range other.Counts {
+		if _, ok := p.Counts[issueCode]; !ok {
+			p.setCount(issueCode, count)
+		}
+	}
+}
+
+// setCount sets the expected number of occurrences of the issue code.
+func (p *Pragma) setCount(issueCode string, count *Count) {
+	if p.Counts == nil {
+		p.Counts = make(map[string]*Count)
+	}
+
+	p.Counts[issueCode] = count
+}
+
+// forbid adds the issue code to the forbidden issue codes.
+func (p *Pragma) forbid(issueCode string) {
+	if p.Forbidden == nil {
+		p.Forbidden = make(map[string]bool)
+	}
+
+	p.Forbidden[issueCode] = true
 }
 
 var (
-	pragmaRegex = regexp.MustCompile(`\s*\[([\w-]+)](:\s*(.*))?`)
-	issueRegex  = regexp2.MustCompile(`\s*(\d+)?\s*("(.*?(?<!\\))")?`, regexp2.None)
+	pragmaRegex = regexp.MustCompile(`\s*\[(!?[\w-]+)](?:@(L\d+|[+-]\d+))?(?:\s*x(\d+)(\+)?)?(:\s*(.*))?`)
+	issueRegex  = regexp2.MustCompile(`\s*(\d+(?:-\d+|:\+\d+(?::\d+)?)?)?\s*((?:re|glob)?"(.*?(?<!\\))")?`, regexp2.None)
+
+	positionRegex = regexp.MustCompile(`^(\d+)(?:-(\d+)|:\+(\d+)(?::(\d+))?)?$`)
 )
 
 // ParsePragma expects a pragma comment (without the comment prefix like // or #) and
@@ -60,20 +196,43 @@ func ParsePragma(comment string) *Pragma {
 		}
 
 		pragma := pragmaMatches[0]
-		if len(pragma) < 4 {
+		if len(pragma) < 7 {
 			continue
 		}
 
 		issueCode := pragma[1]
 
+		// The pragmas with a line offset are kept separately, as they apply to
+		// another line.
+		target := result
+		if pragma[2] != "" {
+			offset, err := parseOffset(pragma[2])
+			if err != nil {
+				continue
+			}
+			target = result.offsetPragma(offset)
+		}
+
+		// Forbidden issue codes don't take counts or column/message pairs.
+		if strings.HasPrefix(issueCode, "!") {
+			target.forbid(issueCode[1:])
+			continue
+		}
+
+		if pragma[3] != "" {
+			if n, err := strconv.Atoi(pragma[3]); err == nil {
+				target.setCount(issueCode, &Count{N: n, AtLeast: pragma[4] != ""})
+			}
+		}
+
 		// Empty column/message pairs
-		if pragma[3] == "" {
-			result.Issues[issueCode] = []*Issue{}
-			result.Hit[issueCode] = false
+		if pragma[6] == "" {
+			target.Issues[issueCode] = []*Issue{}
+			target.Hit[issueCode] = false
 			continue
 		}
 
-		issueCSV := pragma[3]
+		issueCSV := pragma[6]
 		for {
 			match, err := issueRegex.FindStringMatch(issueCSV)
 			if err != nil {
@@ -89,21 +248,23 @@ func ParsePragma(comment string) *Pragma {
 				break
 			}
 
-			message := groups[2].String()
+			message, messageType := groups[2].String(), MessageExact
+			var pattern *regexp.Regexp
 			if message != "" {
-				message, err = strconv.Unquote(message)
+				message, messageType, pattern, err = parseMessage(message)
 				if err != nil {
 					break
 				}
 			}
 
-			issue := &Issue{Message: message, Column: 0}
-			if column, err := strconv.Atoi(groups[1].String()); err == nil {
+			issue := &Issue{Message: message, MessageType: messageType, Pattern: pattern, Column: 0}
+			if column, end, err := parsePosition(groups[1].String()); err == nil {
 				issue.Column = column
+				issue.End = end
 			}
 
-			result.Issues[issueCode] = append(result.Issues[issueCode], issue)
-			result.Hit[issueCode] = false
+			target.Issues[issueCode] = append(target.Issues[issueCode], issue)
+			target.Hit[issueCode] = false
 
 			issueCSV = issueCSV[match.Length:]
 			issueCSV = strings.TrimSpace(issueCSV)
@@ -111,13 +272,54 @@ func ParsePragma(comment string) *Pragma {
 		}
 	}
 
-	if len(result.Issues) == 0 {
+	// The offsets whose issues weren't parsed are dropped.
+	var offsets []*Pragma
+	for _, o := range result.Offsets {
+		if !o.isEmpty() {
+			offsets = append(offsets, o)
+		}
+	}
+	result.Offsets = offsets
+
+	if result.isEmpty() && len(result.Offsets) == 0 {
 		return nil
 	}
 
 	return result
 }
 
+// parsePosition parses the position of an issue in a pragma, which is a column
+// optionally followed by the expected end.
+func parsePosition(s string) (int, *End, error) {
+	match := positionRegex.FindStringSubmatch(s)
+	if match == nil {
+		return 0, nil, errors.New("invalid position")
+	}
+
+	numbers := make([]int, len(match))
+	for i, m := range match[1:] {
+		if m == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(m)
+		if err != nil {
+			return 0, nil, err
+		}
+		numbers[i+1] = n
+	}
+
+	column := numbers[1]
+	switch {
+	case match[2] != "":
+		return column, &End{Column: numbers[2]}, nil
+	case match[3] != "":
+		return column, &End{Lines: numbers[3], Column: numbers[4]}, nil
+	default:
+		return column, nil, nil
+	}
+}
+
 func isPragma(comment string) bool { return pragmaRegex.MatchString(comment) }
 
 func splitWithEscaping(s, separator, escapeString string) []string {
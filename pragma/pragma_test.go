@@ -2,6 +2,7 @@ package pragma
 
 import (
 	"reflect"
+	"regexp"
 	"testing"
 )
 
@@ -155,6 +156,127 @@ func TestParsePragma(t *testing.T) {
 				Hit: map[string]bool{"GO-W1000": false, "GO-W1001": false},
 			},
 		},
+		{
+			name: "pragma with end positions",
+			args: args{comment: `[GO-W1000]: 5-12 "Hello", 5:+2:8, 3:+1 "World"`},
+			want: &Pragma{
+				Issues: map[string][]*Issue{
+					"GO-W1000": {
+						{Column: 5, Message: "Hello", End: &End{Column: 12}},
+						{Column: 5, End: &End{Lines: 2, Column: 8}},
+						{Column: 3, Message: "World", End: &End{Lines: 1}},
+					},
+				},
+				Hit: map[string]bool{"GO-W1000": false},
+			},
+		},
+		{
+			name: "pragma with title patterns",
+			args: args{comment: `[GO-R1005]: 1 re"complexity \d+", glob"function * \"foo\"", "exact \"title\""`},
+			want: &Pragma{
+				Issues: map[string][]*Issue{
+					"GO-R1005": {
+						{
+							Column:      1,
+							Message:     `complexity \d+`,
+							MessageType: MessageRegex,
+							Pattern:     regexp.MustCompile(`complexity \d+`),
+						},
+						{
+							Message:     `function * \"foo\"`,
+							MessageType: MessageGlob,
+							Pattern:     regexp.MustCompile(globRegexp(`function * \"foo\"`)),
+						},
+						{Message: `exact "title"`},
+					},
+				},
+				Hit: map[string]bool{"GO-R1005": false},
+			},
+		},
+		{
+			name: "pragma with invalid title pattern",
+			args: args{comment: `[GO-R1005]: re"complexity (\d+"`},
+			want: nil,
+		},
+		{
+			name: "pragma with forbidden issue codes",
+			args: args{comment: `[!GO-W1008]; [GO-W1000]: 1; [!GO-W1009]: 2`},
+			want: &Pragma{
+				Issues:    map[string][]*Issue{"GO-W1000": {{Column: 1}}},
+				Hit:       map[string]bool{"GO-W1000": false},
+				Forbidden: map[string]bool{"GO-W1008": true, "GO-W1009": true},
+			},
+		},
+		{
+			name: "pragma with only forbidden issue codes",
+			args: args{comment: `[!GO-W1008]`},
+			want: &Pragma{
+				Issues:    map[string][]*Issue{},
+				Hit:       map[string]bool{},
+				Forbidden: map[string]bool{"GO-W1008": true},
+			},
+		},
+		{
+			name: "pragma with occurrence counts",
+			args: args{comment: `[GO-W1000] x3; [GO-W1001] x0+: 5 "Hello"; [GO-W1002]`},
+			want: &Pragma{
+				Issues: map[string][]*Issue{
+					"GO-W1000": {},
+					"GO-W1001": {{Column: 5, Message: "Hello"}},
+					"GO-W1002": {},
+				},
+				Hit: map[string]bool{"GO-W1000": false, "GO-W1001": false, "GO-W1002": false},
+				Counts: map[string]*Count{
+					"GO-W1000": {N: 3},
+					"GO-W1001": {N: 0, AtLeast: true},
+				},
+			},
+		},
+		{
+			name: "pragma with line offsets",
+			args: args{comment: `[GO-W1000]: 5; [GO-W1001]@+2 x2: "Hello"; [!GO-W1002]@-1; [GO-W1003]@L42; [GO-W1004]@+2`},
+			want: &Pragma{
+				Issues: map[string][]*Issue{"GO-W1000": {{Column: 5}}},
+				Hit:    map[string]bool{"GO-W1000": false},
+				Offsets: []*Pragma{
+					{
+						Issues: map[string][]*Issue{
+							"GO-W1001": {{Message: "Hello"}},
+							"GO-W1004": {},
+						},
+						Hit:    map[string]bool{"GO-W1001": false, "GO-W1004": false},
+						Counts: map[string]*Count{"GO-W1001": {N: 2}},
+						Offset: &Offset{Line: 2},
+					},
+					{
+						Issues:    map[string][]*Issue{},
+						Hit:       map[string]bool{},
+						Forbidden: map[string]bool{"GO-W1002": true},
+						Offset:    &Offset{Line: -1},
+					},
+					{
+						Issues: map[string][]*Issue{"GO-W1003": {}},
+						Hit:    map[string]bool{"GO-W1003": false},
+						Offset: &Offset{Line: 42, Absolute: true},
+					},
+				},
+			},
+		},
+		{
+			name: "pragma with only line offsets",
+			args: args{comment: `[GO-W1000]@-2`},
+			want: &Pragma{
+				Issues: map[string][]*Issue{},
+				Hit:    map[string]bool{},
+				Offsets: []*Pragma{
+					{
+						Issues: map[string][]*Issue{"GO-W1000": {}},
+						Hit:    map[string]bool{"GO-W1000": false},
+						Offset: &Offset{Line: -2},
+					},
+				},
+			},
+		},
 		{
 			name: "rust pragma with #[must_use]",
 			args: args{comment: "[RS-E1017]: \"Calling `.hash(_)` on expression with unit-type `#[must_use]`\""},
@@ -175,3 +297,25 @@ func TestParsePragma(t *testing.T) {
 		})
 	}
 }
+
+func TestCount_Matches(t *testing.T) {
+	tests := []struct {
+		count  Count
+		raised int
+		want   bool
+	}{
+		{count: Count{N: 3}, raised: 3, want: true},
+		{count: Count{N: 3}, raised: 2, want: false},
+		{count: Count{N: 3}, raised: 4, want: false},
+		{count: Count{N: 0}, raised: 0, want: true},
+		{count: Count{N: 2, AtLeast: true}, raised: 2, want: true},
+		{count: Count{N: 2, AtLeast: true}, raised: 5, want: true},
+		{count: Count{N: 2, AtLeast: true}, raised: 1, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.count.Matches(tt.raised); got != tt.want {
+			t.Errorf("Count%v.Matches(%d) = %v, want %v", tt.count, tt.raised, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,642 @@
+package pragma
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// String formats the pragma using the pragma syntax, without the comment
+// prefix. The issue codes are sorted to keep the output stable, with the
+// forbidden issue codes last, followed by the pragmas with line offsets.
+func (p *Pragma) String() string {
+	codes := make([]string, 0, len(p.Issues))
+	for code := range p.Issues {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	forbidden := make([]string, 0, len(p.Forbidden))
+	for code := range p.Forbidden {
+		forbidden = append(forbidden, code)
+	}
+	sort.Strings(forbidden)
+
+	offset := ""
+	if p.Offset != nil {
+		offset = p.Offset.String()
+	}
+
+	segments := make([]string, 0, len(codes)+len(forbidden)+len(p.Offsets))
+	for _, code := range codes {
+		segment := "[" + code + "]" + offset
+		if count, ok := p.Counts[code]; ok {
+			segment += " " + count.String()
+		}
+
+		issues := make([]string, 0, len(p.Issues[code]))
+		for _, issue := range p.Issues[code] {
+			issues = append(issues, issue.String())
+		}
+
+		if len(issues) != 0 {
+			segment += ": " + strings.Join(issues, ", ")
+		}
+
+		segments = append(segments, segment)
+	}
+
+	for _, code := range forbidden {
+		segments = append(segments, "[!"+code+"]"+offset)
+	}
+
+	for _, o := range p.Offsets {
+		segments = append(segments, o.String())
+	}
+
+	return strings.Join(segments, "; ")
+}
+
+// String formats the column and the message of the issue using the pragma
+// syntax.
+func (i *Issue) String() string {
+	var parts []string
+	if i.Column != 0 {
+		position := strconv.Itoa(i.Column)
+		if i.End != nil {
+			position += i.End.String()
+		}
+		parts = append(parts, position)
+	}
+
+	if i.Message != "" {
+		// `;` separates the pragmas, so it needs to be escaped.
+		parts = append(parts, strings.ReplaceAll(i.QuotedMessage(), ";", `\;`))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// String formats the count using the pragma syntax.
+func (c *Count) String() string {
+	s := "x" + strconv.Itoa(c.N)
+	if c.AtLeast {
+		s += "+"
+	}
+
+	return s
+}
+
+// String formats the offset using the pragma syntax, to be written after the
+// issue code.
+func (o *Offset) String() string {
+	if o.Absolute {
+		return "@L" + strconv.Itoa(o.Line)
+	}
+
+	if o.Line < 0 {
+		return "@" + strconv.Itoa(o.Line)
+	}
+
+	return "@+" + strconv.Itoa(o.Line)
+}
+
+// String formats the end using the pragma syntax, to be written after the
+// column of the issue.
+func (e *End) String() string {
+	if e.Lines == 0 {
+		return "-" + strconv.Itoa(e.Column)
+	}
+
+	s := ":+" + strconv.Itoa(e.Lines)
+	if e.Column != 0 {
+		s += ":" + strconv.Itoa(e.Column)
+	}
+
+	return s
+}
+
+// pragmaComment is a comment in the file which contains a pragma.
+type pragmaComment struct {
+	*comment
+
+	target int     // line the pragma applies to, starting from 0
+	pragma *Pragma // pragma in the comment
+}
+
+// findComment returns the first comment of a line which contains a pragma,
+// just like the pragma extraction.
+func findComment(comments []*comment) (*pragmaComment, bool) {
+	for _, c := range comments {
+		pragma := ParsePragma(c.text)
+		if pragma == nil {
+			continue
+		}
+
+		return &pragmaComment{comment: c, target: c.target(), pragma: pragma}, true
+	}
+
+	return nil, false
+}
+
+// pragmaComments returns the pragma comments in the lines, along with the
+// line each of them applies to. The pragma on a line containing only a comment
+// applies to the next line which is not a pragma-only comment. The lines of a
+// block comment containing a pragma are pragma-only comments as a whole.
+func (f *File) pragmaComments(lines []string) []*pragmaComment {
+	lineComments := f.scanComments(lines)
+
+	found := make([]*pragmaComment, len(lines))
+	pragmaBlocks := make(map[*blockSpan]bool)
+	for i, comments := range lineComments {
+		if comment, ok := findComment(comments); ok {
+			found[i] = comment
+			if comment.block != nil && comment.wholeLine() {
+				pragmaBlocks[comment.block] = true
+			}
+		}
+	}
+
+	var comments []*pragmaComment
+	var chain []*pragmaComment
+
+	for i := range lines {
+		comment := found[i]
+		if comment != nil && comment.wholeLine() {
+			chain = append(chain, comment)
+			continue
+		}
+
+		if comment == nil && inPragmaBlock(lineComments[i], pragmaBlocks) {
+			continue
+		}
+
+		for _, c := range chain {
+			c.target = i
+		}
+		comments = append(comments, chain...)
+		chain = nil
+
+		if comment != nil {
+			comments = append(comments, comment)
+		}
+	}
+
+	for _, c := range chain {
+		c.target = len(lines)
+	}
+
+	return append(comments, chain...)
+}
+
+// inPragmaBlock reports whether the comments of a line are a part of one of
+// the block comments containing a pragma.
+func inPragmaBlock(comments []*comment, blocks map[*blockSpan]bool) bool {
+	for _, c := range comments {
+		if c.block != nil && blocks[c.block] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// commentPart is a part of a pragma comment which is kept while updating the
+// pragmas, separated from the others using ';'.
+type commentPart struct {
+	text string // text of the part, empty for the pragmas which are added
+
+	// pragma is the pragma of the part if it is written with a line offset,
+	// which applies to the target line, starting from 0.
+	pragma *Pragma
+	target int
+}
+
+// offsetTarget returns the line the pragma with a line offset in the comment
+// applies to, starting from 0.
+func (c *pragmaComment) offsetTarget(p *Pragma) int {
+	return p.Offset.Target(c.line+1) - 1
+}
+
+// commentParts returns the parts of the comment to keep, given whether the
+// pragma applying to each line is replaced. The pragmas with a line offset are
+// kept unless the line they apply to is replaced. The other pragmas are kept
+// unless the target of the comment is replaced. The parts which are not
+// pragmas are always kept. It also reports whether any part was removed.
+func (c *pragmaComment) commentParts(replaced func(line int) bool) ([]*commentPart, bool) {
+	var parts []*commentPart
+	removed := false
+
+	for _, segment := range splitWithEscaping(c.text, ";", "\\") {
+		segment = strings.TrimSpace(segment)
+		if segment == "" {
+			continue
+		}
+
+		if !isPragma(segment) {
+			parts = append(parts, &commentPart{text: segment})
+			continue
+		}
+
+		if p := ParsePragma(segment); p != nil && len(p.Offsets) == 1 && p.isEmpty() {
+			o := p.Offsets[0]
+			if replaced(c.offsetTarget(o)) {
+				removed = true
+				continue
+			}
+
+			parts = append(parts, &commentPart{text: segment, pragma: o, target: c.offsetTarget(o)})
+			continue
+		}
+
+		if replaced(c.target) {
+			removed = true
+			continue
+		}
+
+		parts = append(parts, &commentPart{text: segment})
+	}
+
+	return parts, removed
+}
+
+// UpdatePragmas returns the file content with the pragmas of the provided lines
+// replaced. The pragmas which apply to a line in the map are removed, and the
+// pragma in the map, if non-empty, is written instead. The pragma is written at
+// the end of the line if the line had a pragma there, or if the line has code
+// without any comment. If the line only had pragmas written with a line offset,
+// it is written with the same kind of offset in the comment of the first one.
+// Otherwise, it is written on the line above. The pragmas of the lines inside a
+// multi-line literal are written with a line offset after the literal, as a
+// comment there would be a part of it. The parts of the comments which
+// are not pragmas are left as is, and the line offsets are updated to keep
+// applying to the same lines. The new comments use the first comment prefix,
+// or the first block comment if there are no comment prefixes. The pragmas of
+// the lines outside the file are only removed.
+func (f *File) UpdatePragmas(updates map[int]*Pragma) string {
+	if len(updates) == 0 || (len(f.CommentPrefix) == 0 && len(f.BlockComments) == 0) {
+		return f.Content
+	}
+
+	newline := "\n"
+	if strings.Contains(f.Content, "\r\n") {
+		newline = "\r\n"
+	}
+
+	lines := strings.Split(f.Content, newline)
+	original := append([]string(nil), lines...)
+
+	// The lines in updates start from 1.
+	type lineUpdate struct {
+		inline bool
+		above  bool
+
+		// offset is the comment with the first pragma applying to the line
+		// using a line offset, written as an absolute line if absolute is set.
+		offset   *pragmaComment
+		absolute bool
+	}
+	lineUpdates := make(map[int]*lineUpdate)
+	for line := range updates {
+		lineUpdates[line-1] = &lineUpdate{}
+	}
+
+	replaced := func(line int) bool {
+		_, ok := lineUpdates[line]
+		return ok
+	}
+
+	comments := f.pragmaComments(lines)
+	commentParts := make(map[*pragmaComment][]*commentPart)
+	changed := make(map[*pragmaComment]bool)
+
+	for _, c := range comments {
+		commentParts[c], changed[c] = c.commentParts(replaced)
+
+		if u, ok := lineUpdates[c.target]; ok && !c.pragma.isEmpty() {
+			if c.wholeLine() {
+				u.above = true
+			} else {
+				u.inline = true
+			}
+		}
+
+		for _, o := range c.pragma.Offsets {
+			if u, ok := lineUpdates[c.offsetTarget(o)]; ok && u.offset == nil {
+				u.offset = c
+				u.absolute = o.Offset.Absolute
+			}
+		}
+	}
+
+	// scanners is the state of the comment scanning at the start of each
+	// line, along with the state at the end of the file, to find the comments
+	// on the updated lines and the lines inside a multi-line literal.
+	scanners := make([]commentScanner, len(original)+1)
+	scanner := f.commentScanner()
+	for i, line := range original {
+		scanners[i] = *scanner
+		scanner.scanLine(line, i)
+	}
+	scanners[len(original)] = *scanner
+
+	// The pragmas of the lines inside a multi-line literal are written with a
+	// line offset on the line the literal ends on. In case that line has a
+	// pragma comment, and its own pragmas are kept, they are added to it.
+	for line, p := range updates {
+		i := line - 1
+		if p == nil || p.isEmpty() || i < 0 || i >= len(original) || !insideLiteral(scanners, i) {
+			continue
+		}
+
+		end, ok := literalEnd(scanners, i)
+		if !ok || replaced(end) {
+			continue
+		}
+
+		for _, c := range comments {
+			if c.line == end && !c.wholeLine() {
+				lineUpdates[i].offset = c
+				break
+			}
+		}
+	}
+
+	// The pragmas of the lines which only had pragmas with line offsets are
+	// added to the same comment, in the order of the lines to keep the result
+	// stable.
+	updatedLines := make([]int, 0, len(updates))
+	for line := range updates {
+		updatedLines = append(updatedLines, line)
+	}
+	sort.Ints(updatedLines)
+
+	for _, line := range updatedLines {
+		p := updates[line]
+		u := lineUpdates[line-1]
+		if p == nil || p.isEmpty() || u.offset == nil || u.inline || u.above {
+			continue
+		}
+
+		commentParts[u.offset] = append(commentParts[u.offset], &commentPart{
+			pragma: &Pragma{
+				Issues:    p.Issues,
+				Forbidden: p.Forbidden,
+				Counts:    p.Counts,
+				Offset:    &Offset{Absolute: u.absolute},
+			},
+			target: line - 1,
+		})
+		changed[u.offset] = true
+	}
+
+	// Remove the existing pragmas, keeping the other comments. The line
+	// offsets are updated later, once the lines are known.
+	removedLines := make(map[int]bool)
+	for _, c := range comments {
+		if !changed[c] {
+			continue
+		}
+
+		line, ok := renderComment(c, original[c.line], commentParts[c], nil)
+		if !ok {
+			removedLines[c.line] = true
+			continue
+		}
+
+		lines[c.line] = line
+	}
+
+	numLines := f.LineCount()
+	inserted := make(map[int][]string)
+	literalParts := make(map[int][]*commentPart)
+	for line, p := range updates {
+		if p == nil || p.isEmpty() {
+			continue
+		}
+
+		i := line - 1
+		comment := f.newComment(p.String())
+
+		u := lineUpdates[i]
+		if u.offset != nil && !u.inline && !u.above {
+			continue
+		}
+
+		// The pragmas of the lines outside the file are not written.
+		if i < 0 || i >= numLines {
+			continue
+		}
+
+		code := lines[i]
+		trimmed := strings.TrimSpace(code)
+
+		hasComment := scanners[i].hasComment(code, i)
+		inline := !hasComment && trimmed != "" && (u.inline || !u.above)
+
+		switch {
+		case scanners[i].literal == nil && scanners[i+1].literal != nil:
+			// A comment at the end of the first line of a multi-line literal
+			// would be a part of it.
+			inline = false
+
+		case scanners[i].literal != nil && (scanners[i+1].literal != nil || !inline):
+			// A comment can't be written above a line of a multi-line literal,
+			// nor at the end of it unless the literal ends on the line.
+			if end, ok := literalEnd(scanners, i); ok {
+				literalParts[end] = append(literalParts[end], &commentPart{pragma: p, target: i})
+			}
+			continue
+		}
+
+		if inline {
+			lines[i] = strings.TrimRight(code, " \t") + " " + comment
+			continue
+		}
+
+		indent := code[:len(code)-len(strings.TrimLeft(code, " \t"))]
+		inserted[i] = append(inserted[i], indent+comment)
+	}
+
+	// The pragmas of the lines inside a multi-line literal are written at the
+	// end of the line the literal ends on, or on their own line below it if it
+	// already has a comment. No line is inserted or removed between a line and
+	// the end of its literal, so the offsets are known already.
+	for end, parts := range literalParts {
+		sort.Slice(parts, func(a, b int) bool { return parts[a].target < parts[b].target })
+
+		code := lines[end]
+		below := code != original[end] || scanners[end].hasComment(code, end)
+
+		commentLine := end
+		if below {
+			commentLine++
+		}
+
+		texts := make([]string, 0, len(parts))
+		for _, part := range parts {
+			p := &Pragma{
+				Issues:    part.pragma.Issues,
+				Forbidden: part.pragma.Forbidden,
+				Counts:    part.pragma.Counts,
+				Offset:    &Offset{Line: part.target - commentLine},
+			}
+			texts = append(texts, p.String())
+		}
+		comment := f.newComment(strings.Join(texts, "; "))
+
+		if !below {
+			lines[end] = strings.TrimRight(code, " \t") + " " + comment
+			continue
+		}
+
+		indent := code[:len(code)-len(strings.TrimLeft(code, " \t"))]
+		inserted[end+1] = append([]string{indent + comment}, inserted[end+1]...)
+	}
+
+	// newLines is the line number of each line in the result, starting from 0.
+	newLines := make([]int, len(lines)+1)
+	n := 0
+	for i := range lines {
+		n += len(inserted[i])
+		newLines[i] = n
+		if !removedLines[i] {
+			n++
+		}
+	}
+	newLines[len(lines)] = n
+
+	for _, c := range comments {
+		if removedLines[c.line] || !hasMovedOffsets(c, commentParts[c], newLines) {
+			continue
+		}
+
+		lines[c.line], _ = renderComment(c, original[c.line], commentParts[c], newLines)
+	}
+
+	result := make([]string, 0, len(lines))
+	for i, line := range lines {
+		result = append(result, inserted[i]...)
+		if !removedLines[i] {
+			result = append(result, line)
+		}
+	}
+	result = append(result, inserted[len(lines)]...)
+
+	return strings.Join(result, newline)
+}
+
+// insideLiteral reports whether the line i is inside a multi-line literal, in
+// which case a comment can't be written on it, nor above it, using the state of
+// the comment scanning at the start of each line.
+func insideLiteral(scanners []commentScanner, i int) bool {
+	return scanners[i].literal != nil && scanners[i+1].literal != nil
+}
+
+// literalEnd returns the line the multi-line literal open at the start of the
+// line i ends on, or false if it doesn't end in the file.
+func literalEnd(scanners []commentScanner, i int) (int, bool) {
+	for end := i; end+1 < len(scanners); end++ {
+		if scanners[end+1].literal == nil {
+			return end, true
+		}
+	}
+
+	return 0, false
+}
+
+// newOffset returns the line offset of the pragma in the part, using the line
+// of each original line in the result, or the original lines if nil.
+func newOffset(c *pragmaComment, part *commentPart, newLines []int) *Offset {
+	line, target := c.line, part.target
+	if newLines != nil && target >= 0 && target < len(newLines) {
+		line, target = newLines[line], newLines[target]
+	}
+
+	if part.pragma.Offset.Absolute {
+		return &Offset{Line: target + 1, Absolute: true}
+	}
+
+	return &Offset{Line: target - line}
+}
+
+// hasMovedOffsets reports whether the line offsets of any pragma in the parts
+// change in the result, as the lines are inserted or removed.
+func hasMovedOffsets(c *pragmaComment, parts []*commentPart, newLines []int) bool {
+	for _, part := range parts {
+		if part.pragma != nil && *newOffset(c, part, newLines) != *newOffset(c, part, nil) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// renderComment returns the line of the comment with the provided parts, or
+// false if the line is to be removed. The pragmas with a line offset which
+// changes in the result are formatted again.
+func renderComment(c *pragmaComment, line string, parts []*commentPart, newLines []int) (string, bool) {
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		text := part.text
+		if part.pragma != nil {
+			if offset := newOffset(c, part, newLines); text == "" || *offset != *part.pragma.Offset {
+				p := *part.pragma
+				p.Offset = offset
+				text = p.String()
+			}
+		}
+
+		texts = append(texts, text)
+	}
+
+	before, after := line[:c.start], line[c.end:]
+	rest := strings.Join(texts, "; ")
+
+	switch {
+	case rest != "":
+		return before + joinNonEmpty(c.prefix, rest, c.suffix) + spaced(after), true
+	case c.partial() && (c.prefix != "" || c.suffix != ""):
+		// The start or the end of a block comment spanning multiple lines is
+		// kept.
+		return before + joinNonEmpty(c.prefix, c.suffix) + spaced(after), true
+	case strings.TrimSpace(before) == "" && strings.TrimSpace(after) == "":
+		return "", false
+	case strings.TrimSpace(before) == "":
+		return before + strings.TrimLeft(after, " \t"), true
+	default:
+		return strings.TrimRight(before, " \t") + spaced(after), true
+	}
+}
+
+// newComment returns a comment with the text, using the first comment prefix,
+// or the first block comment if there are no comment prefixes.
+func (f *File) newComment(text string) string {
+	if len(f.CommentPrefix) != 0 {
+		return f.CommentPrefix[0] + " " + text
+	}
+
+	b := f.BlockComments[0]
+	return b.Start + " " + text + " " + b.End
+}
+
+// joinNonEmpty joins the non-empty strings using spaces.
+func joinNonEmpty(s ...string) string {
+	var parts []string
+	for _, part := range s {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// spaced returns s prefixed with a space, unless it is empty.
+func spaced(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return " " + strings.TrimLeft(s, " \t")
+}
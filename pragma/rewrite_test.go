@@ -0,0 +1,384 @@
+package pragma
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// comparePatterns compares the compiled patterns of the messages using their
+// source.
+var comparePatterns = cmp.Comparer(func(a, b *regexp.Regexp) bool {
+	return (a == nil) == (b == nil) && (a == nil || a.String() == b.String())
+})
+
+func TestPragma_String(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+	}{
+		{name: "without column / message", comment: `[GO-W1000]`},
+		{name: "with only columns", comment: `[GO-W1000]: 1, 2`},
+		{name: "with mixed messages and columns", comment: `[GO-W1000]: 1 "Hello", 2, "World"`},
+		{name: "multiple pragmas", comment: `[GO-W1000]: 1 "Hello"; [GO-W1001]: "Hello"`},
+		{name: "quote escaping", comment: `[GO-W1000]: 1 "Hello \"World\""`},
+		{name: "semicolon escaping", comment: `[GO-W1000]: 1 "Hello\; World"; [GO-W1001]`},
+		{name: "end positions", comment: `[GO-W1000]: 5-12 "Hello", 5:+2:8, 1:+1`},
+		{name: "title patterns", comment: `[GO-R1005]: 1 re"complexity \d+", glob"function * is unused"`},
+		{name: "forbidden issue codes", comment: `[GO-W1000]: 1; [!GO-W1008]; [!GO-W1009]`},
+		{name: "occurrence counts", comment: `[GO-W1000] x3; [GO-W1001] x2+: 5 "Hello"`},
+		{name: "line offsets", comment: `[GO-W1000]: 1; [GO-W1001]@+2 x2: 5; [!GO-W1002]@+2; [GO-W1003]@L42`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ParsePragma(tt.comment)
+			if got := p.String(); got != tt.comment {
+				t.Fatalf("Pragma.String() = %s, want %s", got, tt.comment)
+			}
+
+			if !cmp.Equal(ParsePragma(p.String()), p, comparePatterns) {
+				t.Fatalf("Pragma.String() does not round trip, diff: %s",
+					cmp.Diff(p, ParsePragma(p.String()), comparePatterns))
+			}
+		})
+	}
+}
+
+func TestFile_UpdatePragmas(t *testing.T) {
+	type args struct {
+		content       string
+		commentPrefix []string
+		blockComments []BlockComment
+		language      string
+		updates       map[int]*Pragma
+	}
+
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{
+			name: "add pragma at the end of the line",
+			args: args{
+				content: `package main
+
+func main() {
+	a := 10
+	a = a
+}
+`,
+				commentPrefix: []string{"//"},
+				updates: map[int]*Pragma{
+					5: ParsePragma(`[VET-V0002]: 2 "Useless assignment"`),
+				},
+			},
+			want: `package main
+
+func main() {
+	a := 10
+	a = a // [VET-V0002]: 2 "Useless assignment"
+}
+`,
+		},
+		{
+			name: "add forbidden pragma",
+			args: args{
+				content: `package main
+
+var foo = 10
+`,
+				commentPrefix: []string{"//"},
+				updates: map[int]*Pragma{
+					3: ParsePragma(`[!GO-W1008]`),
+				},
+			},
+			want: `package main
+
+var foo = 10 // [!GO-W1008]
+`,
+		},
+		{
+			name: "add pragma above a line with a comment",
+			args: args{
+				content: `def foo():
+    a = a  # assign
+`,
+				commentPrefix: []string{"#"},
+				updates: map[int]*Pragma{
+					2: ParsePragma(`[PYL-W0127]: 5 "Assigning the same variable"`),
+				},
+			},
+			want: `def foo():
+    # [PYL-W0127]: 5 "Assigning the same variable"
+    a = a  # assign
+`,
+		},
+		{
+			name: "update pragmas keeping the position",
+			args: args{
+				content: `package main
+
+// [GO-C5001]: 5 "Old title"
+// [GO-W1000]
+var foo int = 10
+
+func bar() {
+	a := 10
+	a = a // [VET-V0002]: 3
+}
+`,
+				commentPrefix: []string{"//"},
+				updates: map[int]*Pragma{
+					5: ParsePragma(`[GO-C5001]: 9 "Redundant type in variable declaration"`),
+					9: ParsePragma(`[VET-V0002]: 2 "Useless assignment"`),
+				},
+			},
+			want: `package main
+
+// [GO-C5001]: 9 "Redundant type in variable declaration"
+var foo int = 10
+
+func bar() {
+	a := 10
+	a = a // [VET-V0002]: 2 "Useless assignment"
+}
+`,
+		},
+		{
+			name: "remove pragmas keeping other comments",
+			args: args{
+				content: `package main
+
+// [GO-C5001]
+var foo int = 10 // [GO-W1000] // a comment
+var bar = 10 // [GO-W1001]; not a pragma
+`,
+				commentPrefix: []string{"//"},
+				updates: map[int]*Pragma{
+					4: nil,
+					5: nil,
+				},
+			},
+			want: `package main
+
+var foo int = 10 // a comment
+var bar = 10 // not a pragma
+`,
+		},
+		{
+			name: "unchanged lines are left as is",
+			args: args{
+				content: `a = a # [PYL-W0127]
+b = b
+`,
+				commentPrefix: []string{"#"},
+				updates: map[int]*Pragma{
+					2: ParsePragma(`[PYL-W0127]`),
+				},
+			},
+			want: `a = a # [PYL-W0127]
+b = b # [PYL-W0127]
+`,
+		},
+		{
+			name: "update pragmas with line offsets",
+			args: args{
+				content: "package main\n\nvar query = `\nSELECT *\nFROM foo\n` // [GO-W1000]@-1: 1; [GO-W1001]@L4\n\n" +
+					"var bar = 10\n",
+				commentPrefix: []string{"//"},
+				updates: map[int]*Pragma{
+					4: ParsePragma(`[GO-W1001]: 2`),
+					5: nil,
+					8: ParsePragma(`[GO-W1002]`),
+				},
+			},
+			want: "package main\n\nvar query = `\nSELECT *\nFROM foo\n` // [GO-W1001]@L4: 2\n\n" +
+				"var bar = 10 // [GO-W1002]\n",
+		},
+		{
+			name: "line offsets are updated when lines are added",
+			args: args{
+				content:       "package main\n\nvar foo int = 10 // a comment\nvar query = `\nSELECT *\n` // [GO-W1000]@-1; [GO-W1001]@L5\n",
+				commentPrefix: []string{"//"},
+				updates: map[int]*Pragma{
+					3: ParsePragma(`[GO-C5001]`),
+				},
+			},
+			want: "package main\n\n// [GO-C5001]\nvar foo int = 10 // a comment\nvar query = `\nSELECT *\n` // [GO-W1000]@-1; [GO-W1001]@L6\n",
+		},
+		{
+			name: "update pragmas in block comments",
+			args: args{
+				content: `a { color: red; }
+b { color: blue; } /* [CSS-W1001]: 3 */
+/*
+  [CSS-W1002]
+  Regression test
+*/
+c { color: green; }
+`,
+				blockComments: []BlockComment{{Start: "/*", End: "*/"}},
+				updates: map[int]*Pragma{
+					1: ParsePragma(`[CSS-W1000]`),
+					2: ParsePragma(`[CSS-W1001]: 5`),
+					7: nil,
+				},
+			},
+			want: `a { color: red; } /* [CSS-W1000] */
+b { color: blue; } /* [CSS-W1001]: 5 */
+/*
+  Regression test
+*/
+c { color: green; }
+`,
+		},
+		{
+			name: "replace a pragma in a block comment",
+			args: args{
+				content: `package main
+
+/* [GO-W1000] */
+var foo = 10
+`,
+				commentPrefix: []string{"//"},
+				blockComments: []BlockComment{{Start: "/*", End: "*/"}},
+				updates: map[int]*Pragma{
+					4: ParsePragma(`[GO-W1001]`),
+				},
+			},
+			want: `package main
+
+// [GO-W1001]
+var foo = 10
+`,
+		},
+		{
+			name: "pragmas of the lines outside the file are not written",
+			args: args{
+				content: `package main
+
+var foo = 10
+`,
+				commentPrefix: []string{"//"},
+				updates: map[int]*Pragma{
+					0: ParsePragma(`[GO-W1000]`),
+					4: ParsePragma(`[GO-W1001]`),
+				},
+			},
+			want: `package main
+
+var foo = 10
+`,
+		},
+		{
+			name: "add pragma after a string containing the comment prefix",
+			args: args{
+				content: `package main
+
+var url = "http://example.com"
+var bar = 10 // [GO-W1000]
+`,
+				commentPrefix: []string{"//"},
+				language:      "go",
+				updates: map[int]*Pragma{
+					3: ParsePragma(`[GO-W1001]`),
+					4: ParsePragma(`[GO-W1002]`),
+				},
+			},
+			want: `package main
+
+var url = "http://example.com" // [GO-W1001]
+var bar = 10 // [GO-W1002]
+`,
+		},
+		{
+			name: "add pragmas to the lines of a raw string",
+			args: args{
+				content: `package main
+
+const q = ` + "`" + `
+SELECT *
+FROM foo
+` + "`" + `
+`,
+				commentPrefix: []string{"//"},
+				language:      "go",
+				updates: map[int]*Pragma{
+					3: ParsePragma(`[GO-W1000]`),
+					4: ParsePragma(`[SQL-W1000]: 1 "wild"`),
+					5: ParsePragma(`[SQL-W1001]`),
+				},
+			},
+			want: `package main
+
+// [GO-W1000]
+const q = ` + "`" + `
+SELECT *
+FROM foo
+` + "`" + ` // [SQL-W1000]@-2: 1 "wild"; [SQL-W1001]@-1
+`,
+		},
+		{
+			name: "add pragma to a raw string ending on a line with a pragma",
+			args: args{
+				content: `package main
+
+const q = ` + "`" + `
+SELECT *
+` + "`" + ` // [GO-W1000]@-3
+`,
+				commentPrefix: []string{"//"},
+				language:      "go",
+				updates: map[int]*Pragma{
+					4: ParsePragma(`[SQL-W1000]`),
+				},
+			},
+			want: `package main
+
+const q = ` + "`" + `
+SELECT *
+` + "`" + ` // [GO-W1000]@-3; [SQL-W1000]@-1
+`,
+		},
+		{
+			name: "add pragma to a triple-quoted string ending on a line with a comment",
+			args: args{
+				content: `def foo():
+    query = """
+    SELECT *
+    """  # noqa
+`,
+				commentPrefix: []string{"#"},
+				language:      "python",
+				updates: map[int]*Pragma{
+					3: ParsePragma(`[SQL-W1000]`),
+				},
+			},
+			want: `def foo():
+    query = """
+    SELECT *
+    """  # noqa
+    # [SQL-W1000]@-2
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFileWithLanguage(
+				"",
+				tt.args.content,
+				LanguageByName(tt.args.language),
+				tt.args.commentPrefix,
+				tt.args.blockComments...,
+			)
+			got := f.UpdatePragmas(tt.args.updates)
+			if got != tt.want {
+				t.Fatalf("UpdatePragmas() diff: %s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
@@ -1,60 +1,51 @@
 package runner
 
 import (
-	"fmt"
+	"context"
 	"io"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 
-	"github.com/bmatcuk/doublestar/v4"
 	ignore "github.com/sabhiram/go-gitignore"
 )
 
 type AutofixBackup struct {
-	CopiedFiles []string
+	CopiedFiles []string // paths of the files, relative to the CodePath
 	TmpDir      string
 	AutofixDir  string
+	CodePath    string // absolute path of the code path
 	InPlace     bool
 
+	// journalPath is the path of the journal of an in-place backup.
+	journalPath string
 	restoreOnce sync.Once
 }
 
 // NewAutofixBackup backs up the files provided in the FilesGlob pattern to
 // before performing Autofix testing. The backup respects the root `.gitignore`.
+// An in-place backup is recorded in a journal of the code path, which fails the
+// backup if another in-place run is in progress in the same code path. A
+// relative autofixDir is relative to the directory of the config.
 func NewAutofixBackup(
+	ctx context.Context,
 	config *Config,
 	includedFiles map[string]bool,
 	autofixDir string,
-) (*AutofixBackup, error) {
+) (backup *AutofixBackup, err error) {
 	autofixDir = strings.TrimSpace(autofixDir)
 	inPlace := autofixDir == ""
-
-	// Temporarily navigate to the codePath
-	cwd, err := os.Getwd()
-	if err != nil {
-		return nil, err
+	if !inPlace {
+		autofixDir = config.resolvePath(autofixDir)
 	}
 
-	codePath := config.CodePath
-	if strings.TrimSpace(codePath) == "" {
-		codePath = "."
-	}
-
-	err = os.Chdir(codePath)
+	codePathAbs, err := config.codePathAbs()
 	if err != nil {
 		return nil, err
 	}
-	defer func(dir string) {
-		err := os.Chdir(dir)
-		if err != nil {
-			fmt.Println("Chdir error:", err)
-		}
-	}(cwd)
 
-	matches, err := doublestar.FilepathGlob(config.FilesGlob)
+	matches, err := globFiles(codePathAbs, config.FilesGlob)
 	if err != nil {
 		return nil, err
 	}
@@ -64,8 +55,29 @@ func NewAutofixBackup(
 		return nil, err
 	}
 
+	var journalPath string
+	if inPlace {
+		journalPath, err = createAutofixJournal(codePathAbs, tmpDir)
+		if err != nil {
+			_ = os.RemoveAll(tmpDir)
+			return nil, err
+		}
+	}
+
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		_ = os.RemoveAll(tmpDir)
+		if journalPath != "" {
+			_ = os.Remove(journalPath)
+		}
+	}()
+
 	var skipGitignore bool
-	_, err = os.Stat(".gitignore")
+	gitignorePath := filepath.Join(codePathAbs, ".gitignore")
+	_, err = os.Stat(gitignorePath)
 	if err != nil {
 		// Instead of failing if there was an error reading the .gitignore file,
 		// we just skip processing it instead.
@@ -74,24 +86,25 @@ func NewAutofixBackup(
 
 	var gitignore *ignore.GitIgnore
 	if !skipGitignore {
-		gitignore, err = ignore.CompileIgnoreFile(".gitignore")
+		gitignore, err = ignore.CompileIgnoreFile(gitignorePath)
 		if err != nil {
-			_ = os.RemoveAll(tmpDir)
 			return nil, err
 		}
 	}
 
-	backup := &AutofixBackup{
+	backup = &AutofixBackup{
 		CopiedFiles: []string{},
 		TmpDir:      tmpDir,
 		AutofixDir:  autofixDir,
+		CodePath:    codePathAbs,
 		InPlace:     inPlace,
+		journalPath: journalPath,
 	}
 
 	for _, match := range matches {
-		normalized, err := normalizeFilePath(match)
+		normalized, err := normalizeFilePath(filepath.Join(codePathAbs, match))
 		if err != nil {
-			log.Println("Error normalizing the file path for", match, "err:", err)
+			loggerFrom(ctx).Println("Error normalizing the file path for", match, "err:", err)
 			continue
 		}
 
@@ -104,12 +117,12 @@ func NewAutofixBackup(
 		}
 
 		if inPlace {
-			err := copyFile(match, filepath.Join(tmpDir, match))
+			err := copyFile(filepath.Join(codePathAbs, match), filepath.Join(tmpDir, match))
 			if err != nil {
 				return nil, err
 			}
 		} else {
-			err := copyFile(match, filepath.Join(autofixDir, match))
+			err := copyFile(filepath.Join(codePathAbs, match), filepath.Join(autofixDir, match))
 			if err != nil {
 				return nil, err
 			}
@@ -118,22 +131,36 @@ func NewAutofixBackup(
 		backup.CopiedFiles = append(backup.CopiedFiles, match)
 	}
 
-	return backup, os.Chdir(cwd)
+	if inPlace {
+		err = updateAutofixJournal(journalPath, tmpDir, backup.CopiedFiles)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return backup, nil
 }
 
 // RestoreAndDestroy restores the Autofix backup and then deletes the backup
-// directory. It should only be called once per backup.
+// directory along with the journal. It should only be called once per backup.
 func (a *AutofixBackup) RestoreAndDestroy() (err error) {
 	a.restoreOnce.Do(func() {
 		if a.InPlace {
 			for _, file := range a.CopiedFiles {
-				err = copyFile(filepath.Join(a.TmpDir, file), file)
+				err = copyFile(filepath.Join(a.TmpDir, file), filepath.Join(a.CodePath, file))
 				if err != nil {
 					return
 				}
 			}
 
 			err = os.RemoveAll(a.TmpDir)
+			if err != nil {
+				return
+			}
+
+			if a.journalPath != "" {
+				err = os.Remove(a.journalPath)
+			}
 		}
 	})
 
@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -59,12 +60,12 @@ func TestAutofixBackup(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			normalized, err := normalizeFileList(includedFiles, config.CodePath)
+			normalized, err := normalizeFileList(context.Background(), includedFiles, config.CodePath)
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			backup, err := NewAutofixBackup(config, normalized, "")
+			backup, err := NewAutofixBackup(context.Background(), config, normalized, "")
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -152,12 +153,12 @@ func TestAutofixBackup_AutofixDir(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			normalized, err := normalizeFileList(includedFiles, config.CodePath)
+			normalized, err := normalizeFileList(context.Background(), includedFiles, config.CodePath)
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			backup, err := NewAutofixBackup(config, normalized, autofixDir)
+			backup, err := NewAutofixBackup(context.Background(), config, normalized, autofixDir)
 			if err != nil {
 				t.Fatal(err)
 			}
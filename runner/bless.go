@@ -0,0 +1,228 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type GoldenFileStatus int
+
+const (
+	GoldenFileCreated GoldenFileStatus = iota
+	GoldenFileUpdated
+	GoldenFileStale
+	GoldenFileRemoved
+)
+
+func (s GoldenFileStatus) String() string {
+	switch s {
+	case GoldenFileCreated:
+		return "Created"
+	case GoldenFileUpdated:
+		return "Updated"
+	case GoldenFileStale:
+		return "Stale"
+	case GoldenFileRemoved:
+		return "Removed"
+	default:
+		return "Unknown"
+	}
+}
+
+// GoldenFileUpdate is a golden file which was written by Bless, or which is
+// stale as the Autofix no longer changes its file.
+type GoldenFileUpdate struct {
+	File   string // absolute path of the golden file
+	Status GoldenFileStatus
+}
+
+// Bless runs the Autofix script and writes the Autofix'ed content of each file
+// to its golden file. A golden file is only created for a file without one if
+// the Autofix changed it. The golden file of a file which the Autofix no longer
+// changes is reported as stale, and is only removed if prune is set. The files
+// are restored just like when testing Autofix, before the golden files are
+// written, and the golden files matched by the files glob are not blessed
+// themselves. The timeout is used for the Autofix script if it doesn't have a
+// timeout configured.
+func Bless(
+	ctx context.Context,
+	files []string,
+	autofixDir string,
+	timeout time.Duration,
+	prune bool,
+) ([]*GoldenFileUpdate, error) {
+	config, err := ReadConfig(".scatr.toml")
+	if err != nil {
+		return nil, err
+	}
+	config.setDefaultTimeout(timeout)
+
+	if !config.TestAutofix {
+		return nil, errors.New("autofix is not configured")
+	}
+
+	includedFiles, err := normalizeFileList(ctx, files, config.resolvePath(config.CodePath))
+	if err != nil {
+		return nil, err
+	}
+
+	logger := loggerFrom(ctx)
+	logger.Println("Backing up the potentially Autofix'able files")
+	backup, err := NewAutofixBackup(ctx, config, includedFiles, autofixDir)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := blessAutofix(ctx, config, autofixDir, backup)
+	if err != nil {
+		logger.Println("Autofix run error:", err)
+		restoreErr := restoreBackup(ctx, backup)
+		if restoreErr != nil {
+			return nil, fmt.Errorf("autofix err: %s, restore err: %s", err.Error(), restoreErr.Error())
+		}
+
+		return nil, err
+	}
+
+	// The golden files are written once the files are restored, as the golden
+	// files matched by the files glob are restored along with the other files.
+	err = restoreBackup(ctx, backup)
+	if err != nil {
+		return nil, err
+	}
+
+	return writeGoldenFiles(ctx, results, prune)
+}
+
+// autofixResult is the Autofix'ed content of a file, which is written to its
+// golden file.
+type autofixResult struct {
+	goldenFile string
+	content    []byte
+
+	// changed reports whether the Autofix changed the file.
+	changed bool
+}
+
+// blessAutofix runs the Autofix script and returns the Autofix'ed content of
+// each backed up file, except for the golden files.
+func blessAutofix(
+	ctx context.Context,
+	config *Config,
+	autofixDir string,
+	backup *AutofixBackup,
+) ([]*autofixResult, error) {
+	err := runAutofixScript(ctx, config, autofixDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*autofixResult
+	for _, filePath := range backup.CopiedFiles {
+		if filepath.Ext(filePath) == ".golden" {
+			continue
+		}
+
+		codeFilePath := filepath.Join(backup.CodePath, filePath)
+
+		codeFilePathNormalized, err := normalizeFilePath(codeFilePath)
+		if err != nil {
+			return nil, err
+		}
+
+		if isExcluded(codeFilePathNormalized, config.ExcludedDirs) {
+			continue
+		}
+
+		var originalFilePath, autofixedFilePath string
+		if backup.InPlace {
+			originalFilePath = filepath.Join(backup.TmpDir, filePath)
+			autofixedFilePath = codeFilePath
+		} else {
+			originalFilePath = codeFilePath
+			autofixedFilePath = filepath.Join(backup.AutofixDir, filePath)
+		}
+
+		autofixed, err := os.ReadFile(autofixedFilePath)
+		if err != nil {
+			return nil, err
+		}
+
+		original, err := os.ReadFile(originalFilePath)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, &autofixResult{
+			goldenFile: codeFilePath + ".golden",
+			content:    autofixed,
+			changed:    !bytes.Equal(original, autofixed),
+		})
+	}
+
+	return results, nil
+}
+
+// writeGoldenFiles writes the Autofix'ed content of the files to their golden
+// files, and reports the golden files of the files which were not changed as
+// stale, removing them if prune is set.
+func writeGoldenFiles(ctx context.Context, results []*autofixResult, prune bool) ([]*GoldenFileUpdate, error) {
+	var updates []*GoldenFileUpdate
+	for _, result := range results {
+		goldenFilePath := result.goldenFile
+		exists, err := fileExists(goldenFilePath)
+		if err != nil {
+			return nil, err
+		}
+
+		// The file was not Autofix'ed, and so it is not tested for Autofix. A
+		// golden file identical to the file would fail the Autofix test.
+		if !result.changed {
+			if !exists {
+				continue
+			}
+
+			if !prune {
+				updates = append(updates, &GoldenFileUpdate{File: goldenFilePath, Status: GoldenFileStale})
+				continue
+			}
+
+			loggerFrom(ctx).Printf("Removing the golden file %s\n", goldenFilePath)
+			if err := os.Remove(goldenFilePath); err != nil {
+				return nil, err
+			}
+
+			updates = append(updates, &GoldenFileUpdate{File: goldenFilePath, Status: GoldenFileRemoved})
+			continue
+		}
+
+		status := GoldenFileCreated
+		if exists {
+			golden, err := os.ReadFile(goldenFilePath)
+			if err != nil {
+				return nil, err
+			}
+
+			if bytes.Equal(golden, result.content) {
+				continue
+			}
+
+			status = GoldenFileUpdated
+		}
+
+		loggerFrom(ctx).Printf("Writing the golden file %s\n", goldenFilePath)
+		err = os.WriteFile(goldenFilePath, result.content, 0o644)
+		if err != nil {
+			return nil, err
+		}
+
+		updates = append(updates, &GoldenFileUpdate{File: goldenFilePath, Status: status})
+	}
+
+	return updates, nil
+}
@@ -0,0 +1,158 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestBless is an integration test for updating the golden files using the
+// Autofix output. The test data is copied to a temporary directory, as Bless
+// writes the golden files.
+func TestBless(t *testing.T) {
+	tests := []struct {
+		name       string
+		autofixDir bool
+		prune      bool
+	}{
+		{name: "go"},
+		{name: "go", autofixDir: true},
+		{name: "go", prune: true},
+		{name: "go_all_files"},
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testDir := filepath.Join(cwd, "testdata", "bless")
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := copyTestData(t, filepath.Join(testDir, test.name))
+			if err := os.Chdir(dir); err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				if err := os.Chdir(cwd); err != nil {
+					t.Fatal(err)
+				}
+			}()
+
+			var autofixDir string
+			if test.autofixDir {
+				autofixDir = t.TempDir()
+			}
+
+			b, err := os.ReadFile("expected.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var expected map[string]string
+			if err := json.Unmarshal(b, &expected); err != nil {
+				t.Fatal(err)
+			}
+
+			if test.prune {
+				for file, status := range expected {
+					if status == GoldenFileStale.String() {
+						expected[file] = GoldenFileRemoved.String()
+					}
+				}
+			}
+
+			original, err := os.ReadFile("main.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			updates, err := Bless(context.Background(), nil, autofixDir, 0, test.prune)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			codePath, err := normalizeFilePath(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]string)
+			for _, update := range updates {
+				got[relativePath(codePath, update.File)] = update.Status.String()
+
+				if update.Status == GoldenFileRemoved || update.Status == GoldenFileStale {
+					exists, err := fileExists(update.File)
+					if err != nil || exists != (update.Status == GoldenFileStale) {
+						t.Fatalf("unexpected existence of %s: %v, err: %v", update.File, exists, err)
+					}
+
+					continue
+				}
+
+				fixed, err := os.ReadFile(update.File[:len(update.File)-len(".golden")] + ".fixed")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				golden, err := os.ReadFile(update.File)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(golden) != string(fixed) {
+					t.Fatalf("unexpected content of %s: %q", update.File, golden)
+				}
+			}
+
+			if !cmp.Equal(got, expected) {
+				t.Fatalf("unexpected golden file updates, diff: %s", cmp.Diff(expected, got))
+			}
+
+			nested, err := filepath.Glob(filepath.Join(dir, "*.golden.golden"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(nested) != 0 {
+				t.Fatalf("unexpected golden files of golden files: %v", nested)
+			}
+
+			restored, err := os.ReadFile("main.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(restored) != string(original) {
+				t.Fatalf("main.go was not restored, got: %q", restored)
+			}
+		})
+	}
+}
+
+// copyTestData copies the files in the test data directory to a temporary
+// directory and returns its path.
+func copyTestData(t *testing.T, src string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		err := copyFile(filepath.Join(src, entry.Name()), filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
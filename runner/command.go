@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+const (
+	stageChecks    = "checks"
+	stageAutofix   = "autofix"
+	stageProcessor = "processor"
+)
+
+// TimeoutError is returned when the script of a stage does not complete within
+// its timeout.
+type TimeoutError struct {
+	Stage   string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s script timed out after %s", e.Stage, e.Timeout)
+}
+
+// runCommand runs the command of the provided stage. In case the command does
+// not complete within the timeout, it is killed along with the processes in its
+// process group, and a *TimeoutError is returned. A zero timeout disables this.
+// The command is killed the same way if the context is done, in which case the
+// context's error is returned.
+func runCommand(ctx context.Context, cmd *exec.Cmd, stage string, timeout time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := cmd.Start()
+	if err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	// A nil channel blocks forever, so there is no timeout unless it is set.
+	var timedOut <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timedOut = timer.C
+	}
+
+	logger := loggerFrom(ctx)
+	kill := func() {
+		if err := killProcessGroup(cmd); err != nil {
+			logger.Println("Unable to kill the", stage, "script, err:", err)
+		}
+		<-done
+	}
+
+	select {
+	case err := <-done:
+		return err
+
+	case <-timedOut:
+		logger.Printf("The %s script timed out after %s, killing it\n", stage, timeout)
+		kill()
+		return &TimeoutError{Stage: stage, Timeout: timeout}
+
+	case <-ctx.Done():
+		logger.Printf("The %s script was cancelled, killing it\n", stage)
+		kill()
+		return ctx.Err()
+	}
+}
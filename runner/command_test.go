@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestRunTimeout is an integration test for the script timeouts. The scripts in
+// the test data sleep for 30 seconds, so the test fails if a script (or a
+// process started by it) is not killed on a timeout. The test data is copied to
+// a temporary directory, as the Autofix script writes to the files in place.
+func TestRunTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		stage   string
+		timeout time.Duration
+	}{
+		{name: "checks", stage: stageChecks, timeout: 200 * time.Millisecond},
+		{name: "processor", stage: stageProcessor},
+		{name: "autofix", stage: stageAutofix},
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testDir := filepath.Join(cwd, "testdata", "timeout")
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := copyTestData(t, filepath.Join(testDir, test.name))
+			if err := os.Chdir(dir); err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				if err := os.Chdir(cwd); err != nil {
+					t.Fatal(err)
+				}
+			}()
+
+			original, err := os.ReadFile("main.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			startTime := time.Now()
+			_, err = Run(context.Background(), Options{Timeout: test.timeout})
+
+			var timeoutErr *TimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Fatalf("expected a timeout error, got: %v", err)
+			}
+
+			if timeoutErr.Stage != test.stage {
+				t.Fatalf("expected the %s stage to time out, got: %s", test.stage, timeoutErr.Stage)
+			}
+
+			if elapsed := time.Since(startTime); elapsed > 10*time.Second {
+				t.Fatalf("the run took %s, the scripts were not killed", elapsed)
+			}
+
+			restored, err := os.ReadFile("main.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(restored) != string(original) {
+				t.Fatalf("main.go was not restored, got: %q", restored)
+			}
+		})
+	}
+}
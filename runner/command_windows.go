@@ -0,0 +1,32 @@
+//go:build windows
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on Windows, where only the command's process is
+// killed on a timeout.
+func setProcessGroup(*exec.Cmd) {}
+
+// killProcessGroup kills the command's process.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// processExists reports whether a process with the pid is running.
+func processExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	_ = p.Release()
+	return true
+}
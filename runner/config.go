@@ -1,10 +1,19 @@
 package runner
 
-import "github.com/BurntSushi/toml"
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/deepsourcelabs/SCATR/pragma"
+)
 
 type Config struct {
 	FilesGlob     string           `toml:"files"`
-	CommentPrefix []string         `toml:"comment_prefix"`
+	CommentPrefix CommentPrefixes  `toml:"comment_prefix"`
+	Language      string           `toml:"language"`
 	ExcludedDirs  []string         `toml:"excluded_dirs"`
 	CodePath      string           `toml:"code_path"`
 	Checks        TestRunnerConfig `toml:"checks"`
@@ -12,6 +21,66 @@ type Config struct {
 	Processor     ProcessorConfig  `toml:"processor"`
 	TestChecks    bool             `toml:"test_checks"`
 	TestAutofix   bool             `toml:"test_autofix"`
+
+	// CleanEnv starts the scripts from a minimal environment instead of the
+	// environment of the host.
+	CleanEnv bool `toml:"clean_env"`
+
+	// Dir is the absolute path of the directory containing the config. The
+	// relative paths in the config are relative to it, and the scripts are run
+	// in it. The cwd is used in case it is empty.
+	Dir string `toml:"-"`
+}
+
+// CommentPrefixes is the comments which can contain pragmas. In the config, a
+// line comment is written as its prefix, like "//", and a block comment as the
+// pair of its start and end, like ["/*", "*/"].
+type CommentPrefixes struct {
+	Line  []string
+	Block []pragma.BlockComment
+}
+
+// UnmarshalTOML decodes the comment prefixes from the array of the config.
+func (c *CommentPrefixes) UnmarshalTOML(data interface{}) error {
+	values, ok := data.([]interface{})
+	if !ok {
+		return errors.New("comment_prefix must be an array")
+	}
+
+	for _, value := range values {
+		switch value := value.(type) {
+		case string:
+			c.Line = append(c.Line, value)
+
+		case []interface{}:
+			block, err := blockComment(value)
+			if err != nil {
+				return err
+			}
+			c.Block = append(c.Block, block)
+
+		default:
+			return fmt.Errorf("invalid comment_prefix %v, expected a string or a pair of strings", value)
+		}
+	}
+
+	return nil
+}
+
+// blockComment returns the block comment written as the pair of its start and
+// end.
+func blockComment(pair []interface{}) (pragma.BlockComment, error) {
+	if len(pair) == 2 {
+		start, startOk := pair[0].(string)
+		end, endOk := pair[1].(string)
+		if startOk && endOk && start != "" && end != "" {
+			return pragma.BlockComment{Start: start, End: end}, nil
+		}
+	}
+
+	return pragma.BlockComment{}, fmt.Errorf(
+		"invalid block comment %v in comment_prefix, expected its start and end like [\"/*\", \"*/\"]", pair,
+	)
 }
 
 type TestRunnerConfig struct {
@@ -20,12 +89,48 @@ type TestRunnerConfig struct {
 	OutputFile  string   `toml:"output_file"`
 	Interactive bool     `toml:"interactive"`
 	Args        []string `toml:"args"`
+
+	// Env is the environment variables to set for the script, in addition to
+	// the ones set by SCATR.
+	Env map[string]string `toml:"env"`
+
+	// Timeout is the duration after which the script is killed, along with the
+	// processes it started. There is no timeout if it is zero.
+	Timeout time.Duration `toml:"timeout"`
 }
 
 type ProcessorConfig struct {
 	Interpreter    string `toml:"interpreter"`
 	Script         string `toml:"script"`
 	SkipProcessing bool   `toml:"skip_processing"`
+
+	// Format selects a built-in processor for the output file, like "sarif",
+	// instead of the processor script.
+	Format string `toml:"format"`
+
+	// SourcePrefix is stripped from the source of the errors to get the issue
+	// codes with the "checkstyle" format.
+	SourcePrefix string `toml:"source_prefix"`
+
+	// Pattern is the regular expression matching the lines of the output file
+	// with the "regex" format. Its named groups are the fields of the issues.
+	Pattern string `toml:"pattern"`
+
+	// Unmatched is the policy for the lines which don't match the pattern with
+	// the "regex" format, one of "ignore" (default), "warn" or "fail".
+	Unmatched string `toml:"unmatched"`
+
+	// Errors is the policy for the errors reported by the analyzer in the
+	// result, one of "ignore", "warn" (default) or "fail".
+	Errors string `toml:"errors"`
+
+	// Env is the environment variables to set for the processor, in addition
+	// to INPUT_FILE.
+	Env map[string]string `toml:"env"`
+
+	// Timeout is the duration after which the processor is killed, along with
+	// the processes it started. There is no timeout if it is zero.
+	Timeout time.Duration `toml:"timeout"`
 }
 
 func ReadConfig(filePath string) (*Config, error) {
@@ -35,6 +140,11 @@ func ReadConfig(filePath string) (*Config, error) {
 		return nil, err
 	}
 
+	config.Dir, err = filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		return nil, err
+	}
+
 	if config.Checks.Interpreter == "" {
 		// Use the `sh` interpreter by default.
 		config.Checks.Interpreter = "sh"
@@ -62,8 +172,16 @@ func ReadConfig(filePath string) (*Config, error) {
 		config.TestAutofix = meta.IsDefined("autofix")
 	}
 
+	if err := config.Processor.validate(); err != nil {
+		return nil, err
+	}
+
+	if config.Language != "" && pragma.LanguageByName(config.Language) == nil {
+		return nil, fmt.Errorf("unknown language %q", config.Language)
+	}
+
 	for i, dir := range config.ExcludedDirs {
-		normalized, err := normalizeFilePath(dir)
+		normalized, err := normalizeFilePath(config.resolvePath(dir))
 		if err != nil {
 			return nil, err
 		}
@@ -73,3 +191,50 @@ func ReadConfig(filePath string) (*Config, error) {
 
 	return &config, nil
 }
+
+// language returns the language used to find the comments in the file: the
+// language in the config, or else the language of the file's extension.
+func (c *Config) language(path string) *pragma.Language {
+	if c.Language != "" {
+		return pragma.LanguageByName(c.Language)
+	}
+
+	return pragma.LanguageForFile(path)
+}
+
+// setDefaultTimeout sets the timeout of the stages which don't have a timeout
+// configured.
+func (c *Config) setDefaultTimeout(timeout time.Duration) {
+	if c.Checks.Timeout == 0 {
+		c.Checks.Timeout = timeout
+	}
+
+	if c.Autofix.Timeout == 0 {
+		c.Autofix.Timeout = timeout
+	}
+
+	if c.Processor.Timeout == 0 {
+		c.Processor.Timeout = timeout
+	}
+}
+
+// resolvePath returns the path relative to the directory of the config as a
+// path which does not depend on the cwd.
+func (c *Config) resolvePath(path string) string {
+	if c.Dir == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(c.Dir, path)
+}
+
+// codePathAbs returns the normalized absolute path of the code path. Unlike
+// normalizeFilePath, it fails if the code path does not exist.
+func (c *Config) codePathAbs() (string, error) {
+	abs, err := filepath.Abs(c.resolvePath(c.CodePath))
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.EvalSymlinks(abs)
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/BurntSushi/toml"
+	"github.com/deepsourcelabs/SCATR/pragma"
 	"github.com/google/go-cmp/cmp"
 )
 
@@ -15,6 +16,7 @@ func TestReadConfig(t *testing.T) {
 		"no_interpreter",
 		"no_test_checks", "test_checks",
 		"no_test_autofix", "test_autofix",
+		"timeout", "env", "language",
 	}
 
 	cwd, err := os.Getwd()
@@ -37,6 +39,7 @@ func TestReadConfig(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
+			expected.Dir = dir
 
 			if !cmp.Equal(got, expected) {
 				t.Fatalf("got and expected configs don't match, diff: %s",
@@ -57,3 +60,72 @@ func readExpectedConfig(filePath string) (*Config, error) {
 
 	return &config, nil
 }
+
+// TestReadConfig_UnsupportedPolicy checks that the unsupported formats and
+// policies of the processor are reported while reading the config, before
+// running the checks.
+func TestReadConfig_UnsupportedPolicy(t *testing.T) {
+	tests := []string{
+		"[processor]\nerrors = \"error\"",
+		"[processor]\nformat = \"regex\"\nunmatched = \"error\"",
+		"[processor]\nformat = \"pylint\"",
+	}
+
+	for _, config := range tests {
+		path := filepath.Join(t.TempDir(), ".scatr.toml")
+		if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := ReadConfig(path); err == nil {
+			t.Errorf("ReadConfig() of %q: expected an error", config)
+		}
+	}
+}
+
+func TestCommentPrefixes_UnmarshalTOML(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    CommentPrefixes
+		wantErr bool
+	}{
+		{
+			name:   "line comments",
+			config: `comment_prefix = ["//", "#"]`,
+			want:   CommentPrefixes{Line: []string{"//", "#"}},
+		},
+		{
+			name:   "line and block comments",
+			config: `comment_prefix = ["//", ["/*", "*/"], ["<!--", "-->"]]`,
+			want: CommentPrefixes{
+				Line:  []string{"//"},
+				Block: []pragma.BlockComment{{Start: "/*", End: "*/"}, {Start: "<!--", End: "-->"}},
+			},
+		},
+		{
+			name:    "block comment without an end",
+			config:  `comment_prefix = [["/*"]]`,
+			wantErr: true,
+		},
+		{
+			name:    "not an array",
+			config:  `comment_prefix = "//"`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config Config
+			_, err := toml.Decode(tt.config, &config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalTOML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && !cmp.Equal(config.CommentPrefix, tt.want) {
+				t.Fatalf("UnmarshalTOML() diff: %s", cmp.Diff(tt.want, config.CommentPrefix))
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/deepsourcelabs/SCATR/pragma"
@@ -12,17 +14,59 @@ import (
 	"github.com/hexops/gotextdiff/span"
 )
 
-type checksDiff map[string]*issuesForFile
+// ChecksDiff maps the absolute path of each checked file to the issues which
+// did not match its pragmas.
+type ChecksDiff map[string]*FileIssues
 
-type issuesForFile struct {
-	Unexpected []*Issue `json:"unexpected"`
-	NotRaised  []*Issue `json:"not-raised"`
+// FileIssues is the issues raised but not expected in a file, and the expected
+// issues which were not raised.
+type FileIssues struct {
+	Unexpected []*FailedIssue `json:"unexpected"`
+	NotRaised  []*FailedIssue `json:"not-raised"`
+
+	// EndMismatch is the raised issues matching a pragma, except for the end
+	// position, along with the expected end.
+	EndMismatch []*FailedIssue `json:"end-mismatch,omitempty"`
+
+	// Forbidden is the raised issues whose code is forbidden on their line.
+	Forbidden []*FailedIssue `json:"forbidden,omitempty"`
+
+	// CountMismatch is the issues which were raised on a line a different
+	// number of times than expected by the pragma.
+	CountMismatch []*FailedIssue `json:"count-mismatch,omitempty"`
+}
+
+// FailedIssue is an issue which did not match the pragmas of its file, along
+// with what the pragma expected of it, in case it differs from the issue.
+type FailedIssue struct {
+	*Issue
+
+	// TitlePattern is the pattern of the title expected by the pragma of an
+	// issue which was not raised, like re"complexity \d+", in which case the
+	// title is empty.
+	TitlePattern string `json:"title_pattern,omitempty"`
+
+	// ExpectedEnd is the end expected by the pragma of a raised issue whose end
+	// did not match it.
+	ExpectedEnd *Location `json:"expected_end,omitempty"`
+
+	// Occurrences is the number of times an issue was expected to be raised on
+	// its line by the pragma, and the number of times it was raised, in case
+	// they don't match.
+	Occurrences *Occurrences `json:"occurrences,omitempty"`
+}
+
+// Occurrences is the number of occurrences of an issue expected by a pragma,
+// like "3" or "3+" for at least 3, and the number of times it was raised.
+type Occurrences struct {
+	Expected string `json:"expected"`
+	Raised   int    `json:"raised"`
 }
 
-func newIssuesForFile() *issuesForFile {
-	return &issuesForFile{
-		Unexpected: []*Issue{},
-		NotRaised:  []*Issue{},
+func newFileIssues() *FileIssues {
+	return &FileIssues{
+		Unexpected: []*FailedIssue{},
+		NotRaised:  []*FailedIssue{},
 	}
 }
 
@@ -58,10 +102,14 @@ func diffChecksResult(
 	excludedDirs []string,
 	includedFiles map[string]bool,
 	analysisResult *Result,
-) (checksDiff, bool) {
-	result := make(checksDiff)
+) (ChecksDiff, bool) {
+	result := make(ChecksDiff)
 	passed := true
 
+	// raisedCounts is the number of times each issue code with an expected count
+	// was raised on the line of the pragma.
+	raisedCounts := make(map[*pragma.Pragma]map[string]int)
+
 	matchFileNameIssueCodes(files, analysisResult)
 
 	for _, iss := range analysisResult.Issues {
@@ -71,7 +119,7 @@ func diffChecksResult(
 
 		issues, ok := result[iss.Position.fileNormalized]
 		if !ok {
-			issues = newIssuesForFile()
+			issues = newFileIssues()
 			result[iss.Position.fileNormalized] = issues
 		}
 
@@ -82,7 +130,7 @@ func diffChecksResult(
 			}
 
 			if shouldReport(f, iss.Code) {
-				issues.Unexpected = append(issues.Unexpected, iss)
+				issues.Unexpected = append(issues.Unexpected, &FailedIssue{Issue: iss})
 				passed = false
 			}
 			continue
@@ -91,17 +139,32 @@ func diffChecksResult(
 		p, ok := f.Pragmas[iss.Position.Start.Line]
 		if !ok {
 			if shouldReport(f, iss.Code) {
-				issues.Unexpected = append(issues.Unexpected, iss)
+				issues.Unexpected = append(issues.Unexpected, &FailedIssue{Issue: iss})
 				passed = false
 			}
 			continue
 		}
 
+		// The forbidden issue codes are reported regardless of the check mode of
+		// the file.
+		if p.Forbidden[iss.Code] {
+			issues.Forbidden = append(issues.Forbidden, &FailedIssue{Issue: iss})
+			passed = false
+			continue
+		}
+
+		if _, ok := p.Counts[iss.Code]; ok {
+			if raisedCounts[p] == nil {
+				raisedCounts[p] = make(map[string]int)
+			}
+			raisedCounts[p][iss.Code]++
+		}
+
 		pragmaIssues, ok := p.Issues[iss.Code]
 		if !ok {
 			// issue code mismatch
 			if shouldReport(f, iss.Code) {
-				issues.Unexpected = append(issues.Unexpected, iss)
+				issues.Unexpected = append(issues.Unexpected, &FailedIssue{Issue: iss})
 				passed = false
 			}
 			p.Hit[iss.Code] = true
@@ -115,18 +178,40 @@ func diffChecksResult(
 			continue
 		}
 
-		var issueFromPragma *pragma.Issue
+		var issueFromPragma, endMismatch *pragma.Issue
 		for _, issue := range pragmaIssues {
 			if (issue.Column == 0 || issue.Column == iss.Position.Start.Column) &&
-				(issue.Message == "" || issue.Message == iss.Title) {
-				issueFromPragma = issue
-				break
+				issue.MatchesTitle(iss.Title) {
+				if endMatches(issue.End, iss.Position) {
+					issueFromPragma = issue
+					break
+				}
+
+				if endMismatch == nil {
+					endMismatch = issue
+				}
 			}
 		}
 
+		if issueFromPragma == nil && endMismatch != nil {
+			if shouldReport(f, iss.Code) {
+				issues.EndMismatch = append(issues.EndMismatch, &FailedIssue{
+					Issue: iss,
+					ExpectedEnd: &Location{
+						Line:   iss.Position.Start.Line + endMismatch.End.Lines,
+						Column: endMismatch.End.Column,
+					},
+				})
+				passed = false
+			}
+			p.Hit[iss.Code] = true
+			endMismatch.Hit = true
+			continue
+		}
+
 		if issueFromPragma == nil {
 			if shouldReport(f, iss.Code) {
-				issues.Unexpected = append(issues.Unexpected, iss)
+				issues.Unexpected = append(issues.Unexpected, &FailedIssue{Issue: iss})
 				passed = false
 			}
 			p.Hit[iss.Code] = true
@@ -144,39 +229,92 @@ func diffChecksResult(
 
 		issues, ok := result[path]
 		if !ok {
-			issues = newIssuesForFile()
+			issues = newFileIssues()
 			result[path] = issues
 		}
 
-		for line, p := range file.Pragmas {
+		// The lines are sorted, along with the issue codes of the counts, so
+		// that the order of the failures is stable for the printers.
+		lines := make([]int, 0, len(file.Pragmas))
+		for line := range file.Pragmas {
+			lines = append(lines, line)
+		}
+		sort.Ints(lines)
+
+		for _, line := range lines {
+			p := file.Pragmas[line]
 			for code, pragmaIssues := range p.Issues {
 				for _, issue := range pragmaIssues {
 					if !issue.Hit {
 						p.Hit[code] = true
 						if shouldReport(file, code) {
-							issues.NotRaised = append(issues.NotRaised, &Issue{
-								Code:  code,
-								Title: issue.Message,
-								Position: IssuePosition{
-									Start: Location{
-										Line:   line,
-										Column: issue.Column,
+							notRaised := &FailedIssue{
+								Issue: &Issue{
+									Code:  code,
+									Title: issue.Message,
+									Position: IssuePosition{
+										Start: Location{
+											Line:   line,
+											Column: issue.Column,
+										},
 									},
 								},
-							})
+							}
+							if issue.MessageType != pragma.MessageExact {
+								notRaised.Title = ""
+								notRaised.TitlePattern = issue.QuotedMessage()
+							}
+							if issue.End != nil {
+								notRaised.Position.End = &Location{
+									Line:   line + issue.End.Lines,
+									Column: issue.End.Column,
+								}
+							}
+
+							issues.NotRaised = append(issues.NotRaised, notRaised)
 							passed = false
 						}
 					}
 				}
 			}
 
+			codes := make([]string, 0, len(p.Counts))
+			for code := range p.Counts {
+				codes = append(codes, code)
+			}
+			sort.Strings(codes)
+
+			for _, code := range codes {
+				count := p.Counts[code]
+				raised := raisedCounts[p][code]
+				if !count.Matches(raised) && shouldReport(file, code) {
+					issues.CountMismatch = append(issues.CountMismatch, &FailedIssue{
+						Issue: &Issue{
+							Code: code,
+							Position: IssuePosition{
+								Start: Location{Line: line},
+							},
+						},
+						Occurrences: newOccurrences(count, raised),
+					})
+					passed = false
+				}
+			}
+
 			for code, hit := range p.Hit {
+				// The occurrences of the issue codes with a count are checked above.
+				if _, ok := p.Counts[code]; ok {
+					continue
+				}
+
 				if !hit && shouldReport(file, code) {
-					issues.NotRaised = append(issues.NotRaised, &Issue{
-						Code:  code,
-						Title: "",
-						Position: IssuePosition{
-							Start: Location{Line: line},
+					issues.NotRaised = append(issues.NotRaised, &FailedIssue{
+						Issue: &Issue{
+							Code:  code,
+							Title: "",
+							Position: IssuePosition{
+								Start: Location{Line: line},
+							},
 						},
 					})
 					passed = false
@@ -188,6 +326,29 @@ func diffChecksResult(
 	return result, passed
 }
 
+func newOccurrences(count *pragma.Count, raised int) *Occurrences {
+	expected := strconv.Itoa(count.N)
+	if count.AtLeast {
+		expected += "+"
+	}
+
+	return &Occurrences{Expected: expected, Raised: raised}
+}
+
+// endMatches reports whether the position of a raised issue ends at the end
+// expected by a pragma. Any end matches if the pragma doesn't have one.
+func endMatches(end *pragma.End, position IssuePosition) bool {
+	if end == nil {
+		return true
+	}
+
+	if position.End == nil || position.End.Line != position.Start.Line+end.Lines {
+		return false
+	}
+
+	return end.Column == 0 || end.Column == position.End.Column
+}
+
 func shouldReport(file *pragma.File, issueCode string) bool {
 	if file == nil ||
 		file.CheckMode == pragma.CheckAll ||
@@ -218,14 +379,17 @@ func shouldReport(file *pragma.File, issueCode string) bool {
 	return true
 }
 
-type autofixDiff map[string]gotextdiff.Unified
+// AutofixDiff maps the path of each file whose Autofix result differs from its
+// golden file to the diff. The path is relative to the working directory, or
+// absolute if the file is outside of it.
+type AutofixDiff map[string]gotextdiff.Unified
 
 func diffAutofixResult(
 	codePath string,
 	excludedDirs []string,
 	backup *AutofixBackup,
-) (autofixDiff, bool, error) {
-	result := make(autofixDiff)
+) (AutofixDiff, bool, error) {
+	result := make(AutofixDiff)
 
 	for _, filePath := range backup.CopiedFiles {
 		codeFilePath := filepath.Join(codePath, filePath)
@@ -275,7 +439,7 @@ func diffAutofixResult(
 			continue
 		}
 
-		result[codeFilePath] = diff
+		result[workingDirPath(codeFilePath)] = diff
 	}
 
 	return result, len(result) == 0, nil
@@ -324,13 +488,47 @@ func checkIdenticalGoldenFile(
 		}
 
 		if bytes.Equal(originalFile, goldenFile) {
-			result[codeFilePath] = struct{}{}
+			result[workingDirPath(codeFilePath)] = struct{}{}
 		}
 	}
 
 	return result, len(result) == 0, nil
 }
 
+// goldenTestedFiles returns the paths of the backed up files which are tested
+// against a golden file.
+func goldenTestedFiles(
+	codePath string,
+	excludedDirs []string,
+	backup *AutofixBackup,
+) ([]string, error) {
+	var result []string
+
+	for _, filePath := range backup.CopiedFiles {
+		codeFilePath := filepath.Join(codePath, filePath)
+
+		codeFilePathNormalized, err := normalizeFilePath(codeFilePath)
+		if err != nil {
+			return nil, err
+		}
+
+		if isExcluded(codeFilePathNormalized, excludedDirs) {
+			continue
+		}
+
+		exists, err := fileExists(codeFilePath + ".golden")
+		if err != nil {
+			return nil, err
+		}
+
+		if exists {
+			result = append(result, codeFilePath)
+		}
+	}
+
+	return result, nil
+}
+
 func fileExists(filePath string) (bool, error) {
 	_, err := os.Stat(filePath)
 	if err != nil {
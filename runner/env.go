@@ -2,29 +2,71 @@ package runner
 
 import (
 	"os"
+	"sort"
+	"strings"
 )
 
-// setEnv sets the environment variables using the provided key-value map
-func setEnv(env map[string]string) error {
-	for k, v := range env {
-		err := os.Setenv(k, v)
-		if err != nil {
-			return err
+// cleanEnvKeys are the environment variables inherited by the scripts in case
+// clean_env is set in the config.
+var cleanEnvKeys = []string{
+	"PATH", "HOME", "USER", "TMPDIR",
+	// Required on Windows to run the interpreters.
+	"SYSTEMROOT", "COMSPEC", "PATHEXT", "TEMP", "TMP",
+}
+
+// scriptEnv returns the environment of a script in the form of "key=value"
+// strings. It starts from the environment of the host, or only the variables
+// in cleanEnvKeys if cleanEnv is true, and adds the configured variables on top
+// of it. "${VAR}" and "$VAR" in the configured values are expanded using the
+// script's environment only, so that the host variables left out by cleanEnv
+// don't leak back in. The variables set by SCATR, like CODE_PATH, take
+// precedence over the configured variables.
+func scriptEnv(cleanEnv bool, configured, env map[string]string) []string {
+	base := make(map[string]string)
+	if cleanEnv {
+		for _, key := range cleanEnvKeys {
+			if value, ok := os.LookupEnv(key); ok {
+				base[key] = value
+			}
+		}
+	} else {
+		for _, kv := range os.Environ() {
+			key, value, ok := strings.Cut(kv, "=")
+			if ok {
+				base[key] = value
+			}
 		}
 	}
 
-	return nil
-}
+	for key, value := range env {
+		base[key] = value
+	}
 
-// unsetEnv unsets the environment variables using keys from the provided
-// key-value map
-func unsetEnv(env map[string]string) error {
-	for k := range env {
-		err := os.Unsetenv(k)
-		if err != nil {
-			return err
-		}
+	result := make(map[string]string, len(base)+len(configured))
+	for key, value := range base {
+		result[key] = value
+	}
+
+	for key, value := range configured {
+		result[key] = os.Expand(value, func(name string) string {
+			return base[name]
+		})
+	}
+
+	for key, value := range env {
+		result[key] = value
+	}
+
+	keys := make([]string, 0, len(result))
+	for key := range result {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	environ := make([]string, 0, len(keys))
+	for _, key := range keys {
+		environ = append(environ, key+"="+result[key])
 	}
 
-	return nil
+	return environ
 }
@@ -0,0 +1,133 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScriptEnv(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin")
+	t.Setenv("SCATR_HOST", "host")
+
+	tests := []struct {
+		name       string
+		cleanEnv   bool
+		configured map[string]string
+		env        map[string]string
+		want       map[string]string
+	}{
+		{
+			name:       "inherits the host environment",
+			configured: map[string]string{"FOO": "foo"},
+			env:        map[string]string{"CODE_PATH": "/code"},
+			want: map[string]string{
+				"PATH":       "/usr/bin",
+				"SCATR_HOST": "host",
+				"FOO":        "foo",
+				"CODE_PATH":  "/code",
+			},
+		},
+		{
+			name:     "clean environment",
+			cleanEnv: true,
+			env:      map[string]string{"CODE_PATH": "/code"},
+			want: map[string]string{
+				"PATH":       "/usr/bin",
+				"SCATR_HOST": "",
+				"CODE_PATH":  "/code",
+			},
+		},
+		{
+			name:     "expansion",
+			cleanEnv: true,
+			configured: map[string]string{
+				"PATH":    "${CODE_PATH}/bin:$PATH",
+				"HOST":    "${SCATR_HOST}",
+				"MISSING": "${SCATR_MISSING}",
+			},
+			env: map[string]string{"CODE_PATH": "/code"},
+			want: map[string]string{
+				"PATH":      "/code/bin:/usr/bin",
+				"HOST":      "",
+				"MISSING":   "",
+				"CODE_PATH": "/code",
+			},
+		},
+		{
+			name:       "expansion of the host environment",
+			configured: map[string]string{"HOST": "${SCATR_HOST}"},
+			want:       map[string]string{"HOST": "host"},
+		},
+		{
+			name:       "SCATR variables take precedence",
+			configured: map[string]string{"CODE_PATH": "/other"},
+			env:        map[string]string{"CODE_PATH": "/code"},
+			want:       map[string]string{"CODE_PATH": "/code"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := make(map[string]string)
+			for _, kv := range scriptEnv(tt.cleanEnv, tt.configured, tt.env) {
+				key, value, _ := strings.Cut(kv, "=")
+				env[key] = value
+			}
+
+			got := make(map[string]string)
+			for key := range tt.want {
+				got[key] = env[key]
+			}
+
+			if !cmp.Equal(got, tt.want) {
+				t.Fatalf("scriptEnv() diff: %s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestRunScriptEnv(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "env.txt")
+
+	cfg := TestRunnerConfig{
+		Interpreter: "sh",
+		Script:      `echo "$CODE_PATH $OUTPUT_DIR $GREETING" > "$OUTPUT_FILE"; exit 1`,
+		Env: map[string]string{
+			"GREETING":    "hello",
+			"OUTPUT_FILE": output,
+		},
+	}
+
+	config := &Config{CodePath: dir, CleanEnv: true}
+
+	err := runScript(context.Background(), config, cfg, stageAutofix, map[string]string{"OUTPUT_DIR": "/output"})
+	if err == nil {
+		t.Fatal("expected the script to fail")
+	}
+
+	b, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codePath, err := normalizeFilePath(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := strings.TrimSpace(string(b)), codePath+" /output hello"; got != want {
+		t.Fatalf("unexpected script environment, got: %q, want: %q", got, want)
+	}
+
+	for _, key := range []string{"CODE_PATH", "OUTPUT_DIR", "GREETING"} {
+		if _, ok := os.LookupEnv(key); ok {
+			t.Fatalf("%s leaked into the environment of the host", key)
+		}
+	}
+}
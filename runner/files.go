@@ -1,8 +1,9 @@
 package runner
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,30 +12,12 @@ import (
 	"github.com/deepsourcelabs/SCATR/pragma"
 )
 
-func readFiles(config *Config, includedFiles map[string]bool) (map[string]*pragma.File, error) {
-	// Temporarily navigate to the codePath
-	cwd, err := os.Getwd()
-	if err != nil {
-		return nil, err
-	}
-
-	codePath := config.CodePath
-	if strings.TrimSpace(codePath) == "" {
-		codePath = "."
-	}
-
-	err = os.Chdir(codePath)
-	if err != nil {
-		return nil, err
-	}
-	defer func(dir string) {
-		err := os.Chdir(dir)
-		if err != nil {
-			fmt.Println("Chdir error:", err)
-		}
-	}(cwd)
-
-	newCwd, err := os.Getwd()
+func readFiles(
+	ctx context.Context,
+	config *Config,
+	includedFiles map[string]bool,
+) (map[string]*pragma.File, error) {
+	codePath, err := config.codePathAbs()
 	if err != nil {
 		return nil, err
 	}
@@ -44,7 +27,7 @@ func readFiles(config *Config, includedFiles map[string]bool) (map[string]*pragm
 		files := make(map[string]*pragma.File)
 
 		for filePath := range includedFiles {
-			relativePath, err := filepath.Rel(newCwd, filePath)
+			relativePath, err := filepath.Rel(codePath, filePath)
 			if err != nil {
 				return nil, err
 			}
@@ -58,7 +41,7 @@ func readFiles(config *Config, includedFiles map[string]bool) (map[string]*pragm
 				continue
 			}
 
-			file, err := getPragmasForFile(filePath, config.CommentPrefix)
+			file, err := getPragmasForFile(filePath, config)
 			if err != nil {
 				return nil, err
 			}
@@ -71,50 +54,115 @@ func readFiles(config *Config, includedFiles map[string]bool) (map[string]*pragm
 			files[normalized] = file
 		}
 
-		return files, os.Chdir(cwd)
+		return files, nil
 	}
 
-	matches, err := doublestar.FilepathGlob(config.FilesGlob)
+	matches, err := globFiles(codePath, config.FilesGlob)
 	if err != nil {
 		return nil, err
 	}
 
 	files := make(map[string]*pragma.File)
-	for _, filePath := range matches {
-		file, err := getPragmasForFile(filePath, config.CommentPrefix)
+	for _, match := range matches {
+		filePath := filepath.Join(codePath, match)
+
+		file, err := getPragmasForFile(filePath, config)
 		if err != nil {
 			return nil, err
 		}
 
 		normalized, err := normalizeFilePath(filePath)
 		if err != nil {
-			log.Println("Error normalizing the file path", filePath, "err:", err)
+			loggerFrom(ctx).Println("Error normalizing the file path", filePath, "err:", err)
 			continue
 		}
 
 		files[normalized] = file
 	}
 
-	return files, os.Chdir(cwd)
+	return files, nil
+}
+
+// globFiles returns the paths of the files in the directory matching the glob
+// pattern, relative to the directory.
+func globFiles(dir, pattern string) ([]string, error) {
+	matches, err := doublestar.Glob(os.DirFS(dir), filepath.ToSlash(filepath.Clean(pattern)))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, match := range matches {
+		matches[i] = filepath.FromSlash(match)
+	}
+
+	return matches, nil
 }
 
 // normalizeFilePath returns an OS-dependent absolute path used for mapping files
-// to pragmas. It joins the filePath with the codePath.
+// to pragmas. In case the file does not exist, like a file reported by the
+// analyzer which was never created, only its parent directories are resolved.
 func normalizeFilePath(filePath string) (string, error) {
 	abs, err := filepath.Abs(filePath)
 	if err != nil {
 		return "", err
 	}
 
-	return filepath.EvalSymlinks(abs)
+	normalized, err := filepath.EvalSymlinks(abs)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return normalized, err
+	}
+
+	parent := filepath.Dir(abs)
+	if parent == abs {
+		return abs, nil
+	}
+
+	parent, err = normalizeFilePath(parent)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(parent, filepath.Base(abs)), nil
+}
+
+// relativePath returns the path of file relative to base. The file is returned
+// as is if it cannot be made relative.
+func relativePath(base, file string) string {
+	if base == "" || !filepath.IsAbs(file) {
+		return file
+	}
+
+	rel, err := filepath.Rel(base, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return file
+	}
+
+	return rel
+}
+
+// workingDirPath returns the path of file relative to the working directory.
+// The file is returned as is if it cannot be made relative.
+func workingDirPath(file string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return file
+	}
+
+	return relativePath(cwd, file)
 }
 
-func getPragmasForFile(path string, commentPrefix []string) (*pragma.File, error) {
+func getPragmasForFile(path string, config *Config) (*pragma.File, error) {
 	b, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
 	_, name := filepath.Split(path)
-	return pragma.NewFile(name, string(b), commentPrefix), nil
+	return pragma.NewFileWithLanguage(
+		name,
+		string(b),
+		config.language(name),
+		config.CommentPrefix.Line,
+		config.CommentPrefix.Block...,
+	), nil
 }
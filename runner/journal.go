@@ -0,0 +1,163 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// autofixJournalDir is the name of the directory in the temporary directory
+// where the journals of the in-place Autofix runs are written. The journals are
+// kept out of the code paths, so that the Autofix script and the files glob
+// never see them.
+const autofixJournalDir = "scatr_autofix_journals"
+
+// autofixJournalPath returns the path of the journal of the in-place Autofix
+// runs in the absolute code path, creating the directory of the journals.
+func autofixJournalPath(codePath string) (string, error) {
+	dir := filepath.Join(os.TempDir(), autofixJournalDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(codePath))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// autofixJournal records the backup of an in-place Autofix run, so that the
+// files can be restored using `scatr restore` in case the run is killed before
+// restoring them. The journal is created exclusively, so it also acts as a lock
+// which stops two in-place runs from racing on the same code path.
+type autofixJournal struct {
+	PID    int      `json:"pid"`
+	TmpDir string   `json:"tmp_dir"`
+	Files  []string `json:"files"`
+}
+
+// createAutofixJournal creates the journal of the code path for the backup in
+// tmpDir. It fails if the journal already exists.
+func createAutofixJournal(codePath, tmpDir string) (string, error) {
+	path, err := autofixJournalPath(codePath)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if !errors.Is(err, os.ErrExist) {
+			return "", err
+		}
+
+		journal, readErr := readAutofixJournal(path)
+		if readErr == nil && processExists(journal.PID) {
+			return "", fmt.Errorf(
+				"another in-place Autofix run (pid %d) is in progress in %s", journal.PID, codePath,
+			)
+		}
+
+		return "", fmt.Errorf(
+			"a previous in-place Autofix run in %s was interrupted, "+
+				"run `scatr restore` to restore the files from its backup", codePath,
+		)
+	}
+
+	err = json.NewEncoder(f).Encode(&autofixJournal{PID: os.Getpid(), TmpDir: tmpDir})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return "", err
+	}
+
+	return path, nil
+}
+
+// updateAutofixJournal records the backed up files in the journal. The journal
+// is replaced atomically, so that it is never left partially written.
+func updateAutofixJournal(path, tmpDir string, files []string) error {
+	b, err := json.Marshal(&autofixJournal{PID: os.Getpid(), TmpDir: tmpDir, Files: files})
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	err = os.WriteFile(tmpPath, b, 0o644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+func readAutofixJournal(path string) (*autofixJournal, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var journal autofixJournal
+	err = json.Unmarshal(b, &journal)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Autofix journal %s: %w", path, err)
+	}
+
+	return &journal, nil
+}
+
+// Restore restores the files of an in-place Autofix run which was killed before
+// restoring them, using the journal the run left for the code path configured
+// in the .scatr.toml in the cwd. It refuses to restore the files while the run is
+// still in progress, unless force is true. It returns the restored files,
+// relative to the code path, or nil if there is nothing to restore.
+func Restore(force bool) ([]string, error) {
+	config, err := ReadConfig(".scatr.toml")
+	if err != nil {
+		return nil, err
+	}
+
+	codePath, err := config.codePathAbs()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := autofixJournalPath(codePath)
+	if err != nil {
+		return nil, err
+	}
+
+	journal, err := readAutofixJournal(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if !force && journal.PID != os.Getpid() && processExists(journal.PID) {
+		return nil, fmt.Errorf(
+			"the in-place Autofix run (pid %d) is still in progress in %s", journal.PID, codePath,
+		)
+	}
+
+	backup := &AutofixBackup{
+		CopiedFiles: journal.Files,
+		TmpDir:      journal.TmpDir,
+		CodePath:    codePath,
+		InPlace:     true,
+		journalPath: path,
+	}
+
+	log.Println("Restoring the Autofix backup from", journal.TmpDir)
+	err = backup.RestoreAndDestroy()
+	if err != nil {
+		return nil, err
+	}
+
+	return journal.Files, nil
+}
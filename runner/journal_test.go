@@ -0,0 +1,191 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAutofixJournal tests that an in-place backup locks the code path, and
+// that the files of an interrupted run can be restored using its journal.
+func TestAutofixJournal(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := copyTestData(t, filepath.Join(cwd, "testdata", "timeout", "autofix"))
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.Chdir(cwd); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	config, err := ReadConfig(".scatr.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original, err := os.ReadFile("main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := NewAutofixBackup(context.Background(), config, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	codePath, err := config.codePathAbs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	journalPath, err := autofixJournalPath(codePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(journalPath); err != nil {
+		t.Fatalf("the journal was not written, err: %v", err)
+	}
+
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), "journal") {
+			t.Fatalf("the journal was written to the code path as %s", entry.Name())
+		}
+	}
+
+	_, err = NewAutofixBackup(context.Background(), config, nil, "")
+	if err == nil || !strings.Contains(err.Error(), "in progress") {
+		t.Fatalf("expected the second in-place backup to fail, got: %v", err)
+	}
+
+	// Simulate a run which was killed after the Autofix, by writing the pid of
+	// a process which exited to the journal.
+	err = os.WriteFile("main.go", []byte("package main\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exited := exec.Command("sh", "-c", "exit 0")
+	if err := exited.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := json.Marshal(&autofixJournal{
+		PID:    exited.Process.Pid,
+		TmpDir: backup.TmpDir,
+		Files:  backup.CopiedFiles,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(journalPath, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewAutofixBackup(context.Background(), config, nil, "")
+	if err == nil || !strings.Contains(err.Error(), "scatr restore") {
+		t.Fatalf("expected the in-place backup to fail after an interrupted run, got: %v", err)
+	}
+
+	restored, err := Restore(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(restored) != 1 || restored[0] != "main.go" {
+		t.Fatalf("unexpected restored files: %v", restored)
+	}
+
+	got, err := os.ReadFile("main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(original) {
+		t.Fatalf("main.go was not restored, got: %q", got)
+	}
+
+	for _, path := range []string{journalPath, backup.TmpDir} {
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("%s was not removed after restoring, err: %v", path, err)
+		}
+	}
+
+	restored, err = Restore(false)
+	if err != nil || restored != nil {
+		t.Fatalf("expected nothing to restore, got: %v, err: %v", restored, err)
+	}
+}
+
+// TestRunCancel tests that the files are restored when an in-place Autofix run
+// is cancelled.
+func TestRunCancel(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := copyTestData(t, filepath.Join(cwd, "testdata", "timeout", "autofix"))
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.Chdir(cwd); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	original, err := os.ReadFile("main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	_, err = Run(ctx, Options{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the run to be cancelled, got: %v", err)
+	}
+
+	got, err := os.ReadFile("main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(original) {
+		t.Fatalf("main.go was not restored, got: %q", got)
+	}
+
+	codePath, err := normalizeFilePath(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	journalPath, err := autofixJournalPath(codePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(journalPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("the journal was not removed, err: %v", err)
+	}
+}
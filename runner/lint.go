@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// LintFinding is a malformed pragma, or a directive which is never honoured,
+// in one of the tested files.
+type LintFinding struct {
+	File   string
+	Line   int
+	Reason string
+}
+
+func (f *LintFinding) String() string {
+	return fmt.Sprintf("%s:%d: %s", f.File, f.Line, f.Reason)
+}
+
+// Lint reports the pragmas in the files which are not fully parsed, and the
+// scatr-check / scatr-ignore directives which are not on the first line. The
+// paths of the findings are absolute.
+func Lint(files []string) ([]*LintFinding, error) {
+	config, err := ReadConfig(".scatr.toml")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	includedFiles, err := normalizeFileList(ctx, files, config.resolvePath(config.CodePath))
+	if err != nil {
+		return nil, err
+	}
+
+	return lintFiles(ctx, config, includedFiles)
+}
+
+func lintFiles(ctx context.Context, config *Config, includedFiles map[string]bool) ([]*LintFinding, error) {
+	pragmaFiles, err := readFiles(ctx, config, includedFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []*LintFinding
+	for path, file := range pragmaFiles {
+		if isExcluded(path, config.ExcludedDirs) {
+			continue
+		}
+
+		for _, finding := range file.Lint() {
+			findings = append(findings, &LintFinding{
+				File:   path,
+				Line:   finding.Line,
+				Reason: finding.Reason,
+			})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+
+	return findings, nil
+}
+
+// lintPragmas lints the pragmas and prints the findings as warnings, with the
+// paths relative to the directory of the config.
+func lintPragmas(
+	ctx context.Context,
+	config *Config,
+	includedFiles map[string]bool,
+	printer IssuePrinter,
+) ([]*LintFinding, error) {
+	findings, err := lintFiles(ctx, config, includedFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, finding := range findings {
+		printer.PrintWarning(fmt.Sprintf(
+			"%s:%d: %s", relativePath(config.Dir, finding.File), finding.Line, finding.Reason,
+		))
+	}
+
+	return findings, nil
+}
@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLint(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(cwd, "testdata", "lint", "go")
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.Chdir(cwd); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	findings, err := Lint(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		finding.File = relativePath(dir, finding.File)
+		got = append(got, finding.String())
+	}
+
+	expected := []string{
+		`main.go:3: "scatr-ignore:" directive is only honoured on line 1`,
+		`main.go:6: [VET-V0002]: unterminated message "Useless assignment`,
+		`main.go:7: missing columns or messages after [GO-W1001]:`,
+	}
+
+	if !cmp.Equal(got, expected) {
+		t.Fatalf("unexpected lint findings, diff: %s", cmp.Diff(expected, got))
+	}
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"sync"
+)
+
+// runLog is where a run writes its logs, along with the output of its scripts.
+type runLog struct {
+	logger *log.Logger
+	output io.Writer
+}
+
+type runLogKey struct{}
+
+// withRunLog returns a copy of the context using which the runner logs to the
+// logger, and writes the output of the scripts to the output. A run uses the
+// standard logger and the host's stderr otherwise.
+func withRunLog(ctx context.Context, logger *log.Logger, output io.Writer) context.Context {
+	return context.WithValue(ctx, runLogKey{}, &runLog{logger: logger, output: output})
+}
+
+// loggerFrom returns the logger of the run using the context.
+func loggerFrom(ctx context.Context) *log.Logger {
+	if l, ok := ctx.Value(runLogKey{}).(*runLog); ok {
+		return l.logger
+	}
+
+	return log.Default()
+}
+
+// scriptOutputFrom returns the writer for the output of the scripts of the run
+// using the context.
+func scriptOutputFrom(ctx context.Context) io.Writer {
+	if l, ok := ctx.Value(runLogKey{}).(*runLog); ok {
+		return l.output
+	}
+
+	return os.Stderr
+}
+
+// lockedWriter serializes the writes to w, as the logger and the scripts write
+// to the same buffer.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.w.Write(p)
+}
@@ -5,8 +5,10 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/deepsourcelabs/SCATR/pragma"
 	"github.com/fatih/color"
@@ -16,6 +18,9 @@ import (
 const (
 	IssueUnexpected = iota
 	IssueNotRaised
+	IssueEndMismatch
+	IssueForbidden
+	IssueCountMismatch
 )
 
 func getIssueTypeString(failureType int) string {
@@ -24,6 +29,36 @@ func getIssueTypeString(failureType int) string {
 		return "Unexpected Issue"
 	case IssueNotRaised:
 		return "Issue not raised"
+	case IssueEndMismatch:
+		return "Issue end mismatch"
+	case IssueForbidden:
+		return "Forbidden issue raised"
+	case IssueCountMismatch:
+		return "Occurrence count mismatch"
+	}
+
+	return ""
+}
+
+// getIssueDetail returns the details of a failure which are not part of the
+// issue's position and title, like the expected range of an issue whose end
+// did not match. It is empty for the other failures.
+func getIssueDetail(failureType int, issue *FailedIssue) string {
+	switch failureType {
+	case IssueEndMismatch:
+		return fmt.Sprintf(
+			"expected %s, got %s",
+			formatRange(issue.Position.Start, issue.ExpectedEnd),
+			formatRange(issue.Position.Start, issue.Position.End),
+		)
+
+	case IssueCountMismatch:
+		expected := issue.Occurrences.Expected
+		if strings.HasSuffix(expected, "+") {
+			expected = "at least " + strings.TrimSuffix(expected, "+")
+		}
+
+		return fmt.Sprintf("expected %s occurrences, raised %d", expected, issue.Occurrences.Raised)
 	}
 
 	return ""
@@ -31,14 +66,33 @@ func getIssueTypeString(failureType int) string {
 
 type IssuePrinter interface {
 	PrintHeader(header string)
-	PrintIssue(file string, line, column, failureType int, issue *Issue)
+	PrintIssue(file string, line, column, failureType int, issue *FailedIssue)
 	PrintUnifiedDiff(file string, diff gotextdiff.Unified)
 	PrintIdenticalGoldenFile(file string)
 	PrintStatus(passed bool)
 	PrintWarning(warning string)
 }
 
-func printChecksDiff(res checksDiff, printer IssuePrinter) {
+// ReportPrinter is an IssuePrinter which collects the results and writes them
+// as a report once Flush is called after the run.
+type ReportPrinter interface {
+	IssuePrinter
+	Flush() error
+}
+
+// ErrorPrinter is implemented by the report printers which record the error
+// stopping the run, so that the report is still written in that case.
+type ErrorPrinter interface {
+	PrintError(err error)
+}
+
+// TestedFilesPrinter is implemented by the printers which need to know about
+// all the tested files, including the ones which passed.
+type TestedFilesPrinter interface {
+	PrintTestedFiles(files []string)
+}
+
+func printChecksDiff(res ChecksDiff, printer IssuePrinter) {
 	for file, issues := range res {
 		for _, iss := range issues.Unexpected {
 			printer.PrintIssue(
@@ -53,10 +107,31 @@ func printChecksDiff(res checksDiff, printer IssuePrinter) {
 				IssueNotRaised, iss,
 			)
 		}
+
+		for _, iss := range issues.EndMismatch {
+			printer.PrintIssue(
+				file, iss.Position.Start.Line, iss.Position.Start.Column,
+				IssueEndMismatch, iss,
+			)
+		}
+
+		for _, iss := range issues.Forbidden {
+			printer.PrintIssue(
+				file, iss.Position.Start.Line, iss.Position.Start.Column,
+				IssueForbidden, iss,
+			)
+		}
+
+		for _, iss := range issues.CountMismatch {
+			printer.PrintIssue(
+				file, iss.Position.Start.Line, iss.Position.Start.Column,
+				IssueCountMismatch, iss,
+			)
+		}
 	}
 }
 
-func printAutofixDiff(res autofixDiff, printer IssuePrinter) {
+func printAutofixDiff(res AutofixDiff, printer IssuePrinter) {
 	for file, diff := range res {
 		printer.PrintUnifiedDiff(file, diff)
 	}
@@ -68,6 +143,28 @@ func printIdenticalFiles(res identicalGoldenFiles, printer IssuePrinter) {
 	}
 }
 
+// AnalyzerResultPrinter is implemented by the printers which report the errors
+// and the metrics reported by the analyzer.
+type AnalyzerResultPrinter interface {
+	PrintAnalyzerResult(errors []string, metrics []*Metric)
+}
+
+func printAnalyzerResult(result *Result, printer IssuePrinter) {
+	if p, ok := printer.(AnalyzerResultPrinter); ok {
+		p.PrintAnalyzerResult(result.Errors, result.Metrics)
+	}
+}
+
+func printTestedFiles(files []string, printer IssuePrinter) {
+	p, ok := printer.(TestedFilesPrinter)
+	if !ok {
+		return
+	}
+
+	sort.Strings(files)
+	p.PrintTestedFiles(files)
+}
+
 func printUnmatchedFiles(result *Result, files map[string]*pragma.File, printer IssuePrinter) {
 	warnedFiles := make(map[string]struct{})
 
@@ -85,24 +182,148 @@ func printUnmatchedFiles(result *Result, files map[string]*pragma.File, printer
 	}
 }
 
-type DefaultIssuePrinter struct{}
+// printOutsidePragmas warns about the pragmas whose line offset applies to a
+// line outside their file, with the paths relative to the directory of the
+// config. It returns false if there is any such pragma, as it fails the checks.
+func printOutsidePragmas(config *Config, files map[string]*pragma.File, printer IssuePrinter) bool {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		if !isExcluded(path, config.ExcludedDirs) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
 
-func (DefaultIssuePrinter) PrintIssue(file string, line, column, failureType int, issue *Issue) {
-	msg := file + ":" + strconv.Itoa(line)
+	passed := true
+	for _, path := range paths {
+		file := files[path]
+		numLines := file.LineCount()
+
+		lines := make([]int, len(file.OutsideLines))
+		copy(lines, file.OutsideLines)
+		sort.Ints(lines)
+
+		for i, line := range lines {
+			if i > 0 && lines[i-1] == line {
+				continue
+			}
+
+			passed = false
+			printer.PrintWarning(fmt.Sprintf(
+				"%q has a pragma for line %d, which is outside the file of %d lines.",
+				relativePath(config.Dir, path), line, numLines,
+			))
+		}
+	}
+
+	return passed
+}
+
+// suiteStatus returns the status label of a suite in the summary.
+func suiteStatus(result *SuiteResult) string {
+	switch {
+	case result.Err != nil:
+		return "ERROR"
+	case result.Passed:
+		return "PASS "
+	default:
+		return "FAIL "
+	}
+}
+
+// suiteSummary returns the summary of a suite, without its status label.
+func suiteSummary(result *SuiteResult) string {
+	summary := fmt.Sprintf("%s (%s)", result.Suite, result.Duration.Round(time.Millisecond))
+	if result.Err != nil {
+		summary += ": " + result.Err.Error()
+	}
+
+	return summary
+}
+
+// suitesPassed returns the number of suites which passed, as a sentence.
+func suitesPassed(results []*SuiteResult) string {
+	passed := 0
+	for _, result := range results {
+		if result.Passed {
+			passed++
+		}
+	}
+
+	return fmt.Sprintf("%d of %d suites passed", passed, len(results))
+}
+
+// formatPosition returns the "line:column" representation of a position. The
+// column is omitted if it is zero.
+func formatPosition(line, column int) string {
+	pos := strconv.Itoa(line)
 	if column != 0 {
-		msg += ":" + strconv.Itoa(column)
+		pos += ":" + strconv.Itoa(column)
 	}
 
-	msg += " " + getIssueTypeString(failureType) + " "
+	return pos
+}
 
-	switch failureType {
-	case IssueUnexpected:
-		msg += fmt.Sprintf("%s: %q", issue.Code, issue.Title)
+// issueTitle returns the title of the issue, or the pattern of the title in
+// the pragma syntax if the issue has one.
+func issueTitle(issue *FailedIssue) string {
+	if issue.TitlePattern != "" {
+		return issue.TitlePattern
+	}
 
-	case IssueNotRaised:
-		msg += fmt.Sprintf("%s: %q", issue.Code, issue.Title)
+	return issue.Title
+}
+
+// formatIssue returns the code and the quoted title of the issue, or its title
+// pattern, followed by the details of the failure, if any. The occurrence count
+// mismatches are for all the titles, so they don't have one.
+func formatIssue(failureType int, issue *FailedIssue) string {
+	var msg string
+	switch {
+	case failureType == IssueCountMismatch:
+		msg = issue.Code
+	case issue.TitlePattern != "":
+		msg = issue.Code + ": " + issue.TitlePattern
+	default:
+		msg = fmt.Sprintf("%s: %q", issue.Code, issue.Title)
+	}
+
+	if detail := getIssueDetail(failureType, issue); detail != "" {
+		msg += " (" + detail + ")"
 	}
 
+	return msg
+}
+
+// formatRange returns the "line:column-line:column" representation of a
+// range, or the start position if the end is nil.
+func formatRange(start Location, end *Location) string {
+	if end == nil {
+		return formatPosition(start.Line, start.Column)
+	}
+
+	return formatPosition(start.Line, start.Column) + "-" + formatPosition(end.Line, end.Column)
+}
+
+// hunkString returns the unified diff representation of a single hunk of the
+// provided diff.
+func hunkString(diff gotextdiff.Unified, hunk *gotextdiff.Hunk) string {
+	return fmt.Sprint(gotextdiff.Unified{
+		From:  diff.From,
+		To:    diff.To,
+		Hunks: []*gotextdiff.Hunk{hunk},
+	})
+}
+
+type DefaultIssuePrinter struct{}
+
+func (DefaultIssuePrinter) PrintIssue(file string, line, column, failureType int, issue *FailedIssue) {
+	msg := file + ":" + strconv.Itoa(line)
+	if column != 0 {
+		msg += ":" + strconv.Itoa(column)
+	}
+
+	msg += " " + getIssueTypeString(failureType) + " " + formatIssue(failureType, issue)
 	fmt.Println(msg)
 }
 
@@ -115,18 +336,33 @@ func (DefaultIssuePrinter) PrintHeader(header string) {
 }
 
 func (DefaultIssuePrinter) PrintUnifiedDiff(file string, diff gotextdiff.Unified) {
-	fmt.Println(file)
+	fmt.Println(workingDirPath(file))
 	fmt.Println(diff)
 }
 
 func (DefaultIssuePrinter) PrintIdenticalGoldenFile(file string) {
-	fmt.Printf("%s: file is identical to the golden file\n", file)
+	fmt.Printf("%s: file is identical to the golden file\n", workingDirPath(file))
 }
 
 func (DefaultIssuePrinter) PrintWarning(warning string) {
 	fmt.Println("Warn:", warning)
 }
 
+func (DefaultIssuePrinter) PrintSuiteHeader(suite string) {
+	fmt.Println("===", suite)
+}
+
+func (DefaultIssuePrinter) PrintSummary(results []*SuiteResult) {
+	fmt.Println()
+	fmt.Println("Summary")
+
+	for _, result := range results {
+		fmt.Println(suiteStatus(result), suiteSummary(result))
+	}
+
+	fmt.Println(suitesPassed(results))
+}
+
 type PrettyIssuePrinter struct {
 	cwd          string
 	filesPrinted map[string]bool
@@ -162,7 +398,7 @@ func NewPrettyIssuePrinter() *PrettyIssuePrinter {
 
 func (p *PrettyIssuePrinter) PrintIssue(
 	file string, line, column,
-	failureType int, issue *Issue,
+	failureType int, issue *FailedIssue,
 ) {
 	if !p.filesPrinted[file] {
 		p.filesPrinted[file] = true
@@ -200,7 +436,14 @@ func (p *PrettyIssuePrinter) PrintIssue(
 		strings.Repeat(" ", indentAfterCode),
 	)
 
-	fmt.Printf("%s  %s\n", color.RedString(getIssueTypeString(failureType)), issue.Title)
+	title := issueTitle(issue)
+	if detail := getIssueDetail(failureType, issue); detail != "" && title != "" {
+		title += "  (" + detail + ")"
+	} else if detail != "" {
+		title = "(" + detail + ")"
+	}
+
+	fmt.Printf("%s  %s\n", color.RedString(getIssueTypeString(failureType)), title)
 }
 
 func (*PrettyIssuePrinter) PrintStatus(passed bool) {
@@ -219,7 +462,7 @@ func (*PrettyIssuePrinter) PrintHeader(header string) {
 
 func (p *PrettyIssuePrinter) PrintUnifiedDiff(file string, diff gotextdiff.Unified) {
 	fmt.Println()
-	p.fileColor.Println("#", file)
+	p.fileColor.Println("#", relativePath(p.cwd, file))
 
 	for _, hunk := range diff.Hunks {
 		fmt.Println()
@@ -249,7 +492,7 @@ func (p *PrettyIssuePrinter) PrintUnifiedDiff(file string, diff gotextdiff.Unifi
 }
 
 func (p *PrettyIssuePrinter) PrintIdenticalGoldenFile(file string) {
-	p.fileColor.Printf("# %s: Input file identical to the golden file\n", file)
+	p.fileColor.Printf("# %s: Input file identical to the golden file\n", relativePath(p.cwd, file))
 }
 
 func (p *PrettyIssuePrinter) PrintWarning(warning string) {
@@ -258,11 +501,33 @@ func (p *PrettyIssuePrinter) PrintWarning(warning string) {
 	p.warnTextColor.Println(warning)
 }
 
+func (*PrettyIssuePrinter) PrintSuiteHeader(suite string) {
+	fmt.Println()
+	color.New(color.FgCyan, color.Bold).Println("Suite", suite)
+}
+
+func (*PrettyIssuePrinter) PrintSummary(results []*SuiteResult) {
+	fmt.Println()
+	color.New(color.FgYellow, color.Bold, color.Underline).Println("Summary")
+
+	for _, result := range results {
+		if result.Passed {
+			color.New(color.FgGreen).Print(suiteStatus(result))
+		} else {
+			color.New(color.FgHiRed).Print(suiteStatus(result))
+		}
+		fmt.Println(" " + suiteSummary(result))
+	}
+
+	fmt.Println()
+	fmt.Println(suitesPassed(results))
+}
+
 type NOPIssuePrinter struct{}
 
 func (NOPIssuePrinter) PrintHeader(string) {}
 
-func (NOPIssuePrinter) PrintIssue(string, int, int, int, *Issue) {}
+func (NOPIssuePrinter) PrintIssue(string, int, int, int, *FailedIssue) {}
 
 func (NOPIssuePrinter) PrintUnifiedDiff(string, gotextdiff.Unified) {}
 
@@ -271,3 +536,105 @@ func (NOPIssuePrinter) PrintIdenticalGoldenFile(string) {}
 func (NOPIssuePrinter) PrintStatus(bool) {}
 
 func (NOPIssuePrinter) PrintWarning(string) {}
+
+// MultiIssuePrinter forwards everything printed to each of its printers.
+type MultiIssuePrinter []IssuePrinter
+
+func (m MultiIssuePrinter) PrintHeader(header string) {
+	for _, p := range m {
+		p.PrintHeader(header)
+	}
+}
+
+func (m MultiIssuePrinter) PrintIssue(file string, line, column, failureType int, issue *FailedIssue) {
+	for _, p := range m {
+		p.PrintIssue(file, line, column, failureType, issue)
+	}
+}
+
+func (m MultiIssuePrinter) PrintUnifiedDiff(file string, diff gotextdiff.Unified) {
+	for _, p := range m {
+		p.PrintUnifiedDiff(file, diff)
+	}
+}
+
+func (m MultiIssuePrinter) PrintIdenticalGoldenFile(file string) {
+	for _, p := range m {
+		p.PrintIdenticalGoldenFile(file)
+	}
+}
+
+func (m MultiIssuePrinter) PrintStatus(passed bool) {
+	for _, p := range m {
+		p.PrintStatus(passed)
+	}
+}
+
+func (m MultiIssuePrinter) PrintWarning(warning string) {
+	for _, p := range m {
+		p.PrintWarning(warning)
+	}
+}
+
+func (m MultiIssuePrinter) PrintError(err error) {
+	for _, p := range m {
+		if p, ok := p.(ErrorPrinter); ok {
+			p.PrintError(err)
+		}
+	}
+}
+
+func (m MultiIssuePrinter) PrintTestedFiles(files []string) {
+	for _, p := range m {
+		if p, ok := p.(TestedFilesPrinter); ok {
+			p.PrintTestedFiles(files)
+		}
+	}
+}
+
+func (m MultiIssuePrinter) PrintAnalyzerResult(errors []string, metrics []*Metric) {
+	for _, p := range m {
+		if p, ok := p.(AnalyzerResultPrinter); ok {
+			p.PrintAnalyzerResult(errors, metrics)
+		}
+	}
+}
+
+func (m MultiIssuePrinter) PrintSuiteHeader(suite string) {
+	for _, p := range m {
+		if p, ok := p.(SuitePrinter); ok {
+			p.PrintSuiteHeader(suite)
+		}
+	}
+}
+
+func (m MultiIssuePrinter) PrintSummary(results []*SuiteResult) {
+	for _, p := range m {
+		if p, ok := p.(SuitePrinter); ok {
+			p.PrintSummary(results)
+		}
+	}
+}
+
+func (m MultiIssuePrinter) setReplayTime(t time.Time) {
+	for _, p := range m {
+		if p, ok := p.(replayTimePrinter); ok {
+			p.setReplayTime(t)
+		}
+	}
+}
+
+// Flush flushes all the printers which are a ReportPrinter. It returns the
+// first error encountered.
+func (m MultiIssuePrinter) Flush() error {
+	var firstErr error
+	for _, p := range m {
+		if p, ok := p.(ReportPrinter); ok {
+			if err := p.Flush(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"time"
+
+	"github.com/hexops/gotextdiff"
+)
+
+// replayTimePrinter is implemented by the printers which time the results they
+// are given, like the durations of the JUnit test suites. The printer uses the
+// provided time as the time of the following calls, as they are replayed after
+// being made, or the current time again if it is zero.
+type replayTimePrinter interface {
+	setReplayTime(t time.Time)
+}
+
+type bufferedCall struct {
+	time time.Time
+	call func(IssuePrinter)
+}
+
+// bufferedIssuePrinter records the calls made to it, so that the results of a
+// suite run in parallel are printed in one piece once the suite completes.
+type bufferedIssuePrinter struct {
+	calls []bufferedCall
+}
+
+func (b *bufferedIssuePrinter) record(call func(IssuePrinter)) {
+	b.calls = append(b.calls, bufferedCall{time: time.Now(), call: call})
+}
+
+// replay makes the recorded calls on the printer, in the order they were made,
+// along with the time each call was made if the printer times the results.
+func (b *bufferedIssuePrinter) replay(printer IssuePrinter) {
+	timePrinter, timed := printer.(replayTimePrinter)
+	for _, call := range b.calls {
+		if timed {
+			timePrinter.setReplayTime(call.time)
+		}
+		call.call(printer)
+	}
+
+	if timed {
+		timePrinter.setReplayTime(time.Time{})
+	}
+}
+
+func (b *bufferedIssuePrinter) PrintHeader(header string) {
+	b.record(func(p IssuePrinter) { p.PrintHeader(header) })
+}
+
+func (b *bufferedIssuePrinter) PrintIssue(file string, line, column, failureType int, issue *FailedIssue) {
+	b.record(func(p IssuePrinter) { p.PrintIssue(file, line, column, failureType, issue) })
+}
+
+func (b *bufferedIssuePrinter) PrintUnifiedDiff(file string, diff gotextdiff.Unified) {
+	b.record(func(p IssuePrinter) { p.PrintUnifiedDiff(file, diff) })
+}
+
+func (b *bufferedIssuePrinter) PrintIdenticalGoldenFile(file string) {
+	b.record(func(p IssuePrinter) { p.PrintIdenticalGoldenFile(file) })
+}
+
+func (b *bufferedIssuePrinter) PrintStatus(passed bool) {
+	b.record(func(p IssuePrinter) { p.PrintStatus(passed) })
+}
+
+func (b *bufferedIssuePrinter) PrintWarning(warning string) {
+	b.record(func(p IssuePrinter) { p.PrintWarning(warning) })
+}
+
+func (b *bufferedIssuePrinter) PrintTestedFiles(files []string) {
+	b.record(func(p IssuePrinter) {
+		if printer, ok := p.(TestedFilesPrinter); ok {
+			printer.PrintTestedFiles(files)
+		}
+	})
+}
+
+func (b *bufferedIssuePrinter) PrintAnalyzerResult(errors []string, metrics []*Metric) {
+	b.record(func(p IssuePrinter) {
+		if printer, ok := p.(AnalyzerResultPrinter); ok {
+			printer.PrintAnalyzerResult(errors, metrics)
+		}
+	})
+}
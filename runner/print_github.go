@@ -0,0 +1,139 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hexops/gotextdiff"
+)
+
+// GitHubIssuePrinter prints the results as GitHub Actions workflow commands, so
+// that they are shown as annotations on the files of a pull request. The file
+// paths are relative to the GITHUB_WORKSPACE, or to the cwd if it is not set.
+type GitHubIssuePrinter struct {
+	w         io.Writer
+	workspace string
+}
+
+func NewGitHubIssuePrinter(w io.Writer) *GitHubIssuePrinter {
+	workspace := os.Getenv("GITHUB_WORKSPACE")
+	if workspace == "" {
+		cwd, err := os.Getwd()
+		if err == nil {
+			workspace = cwd
+		}
+	}
+
+	if workspace != "" {
+		if normalized, err := normalizeFilePath(workspace); err == nil {
+			workspace = normalized
+		}
+	}
+
+	return &GitHubIssuePrinter{w: w, workspace: workspace}
+}
+
+// command prints a workflow command with the provided properties. The
+// properties are a list of key-value pairs, and the ones with an empty value
+// are skipped.
+func (p *GitHubIssuePrinter) command(name, message string, properties ...string) {
+	var props []string
+	for i := 0; i+1 < len(properties); i += 2 {
+		if properties[i+1] == "" {
+			continue
+		}
+
+		props = append(props, properties[i]+"="+escapeGitHubProperty(properties[i+1]))
+	}
+
+	cmd := "::" + name
+	if len(props) != 0 {
+		cmd += " " + strings.Join(props, ",")
+	}
+
+	fmt.Fprintf(p.w, "%s::%s\n", cmd, escapeGitHubData(message))
+}
+
+// file returns the path of the file relative to the workspace. The Autofix
+// results are given relative to the cwd, which may differ from the workspace.
+func (p *GitHubIssuePrinter) file(file string) string {
+	if normalized, err := normalizeFilePath(file); err == nil {
+		file = normalized
+	}
+
+	return relativePath(p.workspace, file)
+}
+
+func (*GitHubIssuePrinter) PrintHeader(string) {}
+
+func (p *GitHubIssuePrinter) PrintIssue(file string, line, column, failureType int, issue *FailedIssue) {
+	var lineStr, colStr string
+	if line > 0 {
+		lineStr = strconv.Itoa(line)
+	}
+	if column > 0 {
+		colStr = strconv.Itoa(column)
+	}
+
+	p.command(
+		"error", formatIssue(failureType, issue),
+		"file", p.file(file),
+		"line", lineStr,
+		"col", colStr,
+		"title", getIssueTypeString(failureType),
+	)
+}
+
+func (p *GitHubIssuePrinter) PrintUnifiedDiff(file string, diff gotextdiff.Unified) {
+	for _, hunk := range diff.Hunks {
+		line := hunk.FromLine
+		if line == 0 {
+			line = 1
+		}
+
+		p.command(
+			"error", hunkString(diff, hunk),
+			"file", p.file(file),
+			"line", strconv.Itoa(line),
+			"title", "Autofix result differs from the golden file",
+		)
+	}
+}
+
+func (p *GitHubIssuePrinter) PrintIdenticalGoldenFile(file string) {
+	p.command(
+		"error", "Input file identical to the golden file",
+		"file", p.file(file),
+		"title", "Identical golden file",
+	)
+}
+
+func (*GitHubIssuePrinter) PrintStatus(bool) {}
+
+func (p *GitHubIssuePrinter) PrintWarning(warning string) {
+	p.command("warning", warning)
+}
+
+var (
+	gitHubDataReplacer = strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+	)
+	gitHubPropertyReplacer = strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+		":", "%3A",
+		",", "%2C",
+	)
+)
+
+// escapeGitHubData escapes the message of a workflow command.
+func escapeGitHubData(s string) string { return gitHubDataReplacer.Replace(s) }
+
+// escapeGitHubProperty escapes a property value of a workflow command.
+func escapeGitHubProperty(s string) string { return gitHubPropertyReplacer.Replace(s) }
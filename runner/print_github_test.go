@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestGitHubIssuePrinter tests the workflow commands printed for the results
+func TestGitHubIssuePrinter(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_WORKSPACE", filepath.Dir(cwd))
+
+	buf := &bytes.Buffer{}
+	printer := NewGitHubIssuePrinter(buf)
+
+	file := filepath.Join(cwd, "testdata", "main.go")
+	printer.PrintIssue(file, 4, 9, IssueUnexpected, &FailedIssue{Issue: &Issue{Code: "GO-C5001", Title: "Redundant type"}})
+	printer.PrintIssue(file, 9, 0, IssueNotRaised, &FailedIssue{Issue: &Issue{Code: "VET-V0002"}})
+	printer.PrintIdenticalGoldenFile(filepath.Join("testdata", "main.go"))
+	printer.PrintWarning("100% of\nthe warnings")
+
+	expected := `::error file=runner/testdata/main.go,line=4,col=9,title=Unexpected Issue::GO-C5001: "Redundant type"
+::error file=runner/testdata/main.go,line=9,title=Issue not raised::VET-V0002: ""
+::error file=runner/testdata/main.go,title=Identical golden file::Input file identical to the golden file
+::warning::100%25 of%0Athe warnings
+`
+
+	if got := buf.String(); got != expected {
+		t.Fatalf("unexpected workflow commands, diff: %s", cmp.Diff(expected, got))
+	}
+}
@@ -0,0 +1,220 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/hexops/gotextdiff"
+)
+
+type jsonReport struct {
+	Passed   bool              `json:"passed"`
+	Checks   jsonChecksReport  `json:"checks"`
+	Autofix  jsonAutofixReport `json:"autofix"`
+	Warnings []string          `json:"warnings"`
+	Error    string            `json:"error,omitempty"`
+	Suites   []*jsonSuite      `json:"suites,omitempty"`
+}
+
+type jsonSuite struct {
+	Suite    string  `json:"suite"`
+	Passed   bool    `json:"passed"`
+	Error    string  `json:"error,omitempty"`
+	Duration float64 `json:"duration"` // in seconds
+}
+
+type jsonChecksReport struct {
+	Files map[string]*FileIssues `json:"files"`
+
+	// Errors and Metrics are the ones reported by the analyzer.
+	Errors  []string  `json:"errors"`
+	Metrics []*Metric `json:"metrics"`
+}
+
+type jsonAutofixReport struct {
+	Diffs     map[string]*jsonAutofixDiff `json:"diffs"`
+	Identical []string                    `json:"identical"`
+}
+
+type jsonAutofixDiff struct {
+	Unified string      `json:"unified"`
+	Hunks   []*jsonHunk `json:"hunks"`
+}
+
+type jsonHunk struct {
+	FromLine int             `json:"from_line"`
+	ToLine   int             `json:"to_line"`
+	Lines    []*jsonHunkLine `json:"lines"`
+}
+
+type jsonHunkLine struct {
+	Kind    string `json:"kind"`
+	Content string `json:"content"`
+}
+
+// JSONIssuePrinter collects the test results and writes them as a JSON
+// document when flushed. The format of the document is documented in the
+// README.
+type JSONIssuePrinter struct {
+	w   io.Writer
+	cwd string
+
+	report *jsonReport
+}
+
+func NewJSONIssuePrinter(w io.Writer) *JSONIssuePrinter {
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = ""
+	}
+
+	return &JSONIssuePrinter{
+		w:   w,
+		cwd: cwd,
+		report: &jsonReport{
+			Passed: true,
+			Checks: jsonChecksReport{
+				Files:   make(map[string]*FileIssues),
+				Errors:  []string{},
+				Metrics: []*Metric{},
+			},
+			Autofix: jsonAutofixReport{
+				Diffs:     make(map[string]*jsonAutofixDiff),
+				Identical: []string{},
+			},
+			Warnings: []string{},
+		},
+	}
+}
+
+func (*JSONIssuePrinter) PrintHeader(string) {}
+
+func (p *JSONIssuePrinter) PrintIssue(file string, _, _, failureType int, issue *FailedIssue) {
+	name := relativePath(p.cwd, file)
+	issues, ok := p.report.Checks.Files[name]
+	if !ok {
+		issues = newFileIssues()
+		p.report.Checks.Files[name] = issues
+	}
+
+	// The issue is copied as the pragma issues which were not raised don't
+	// have the file set.
+	iss := *issue
+	reported := *issue.Issue
+	iss.Issue = &reported
+	if iss.Position.File == "" {
+		iss.Position.File = name
+	}
+
+	switch failureType {
+	case IssueUnexpected:
+		issues.Unexpected = append(issues.Unexpected, &iss)
+	case IssueNotRaised:
+		issues.NotRaised = append(issues.NotRaised, &iss)
+	case IssueEndMismatch:
+		issues.EndMismatch = append(issues.EndMismatch, &iss)
+	case IssueForbidden:
+		issues.Forbidden = append(issues.Forbidden, &iss)
+	case IssueCountMismatch:
+		issues.CountMismatch = append(issues.CountMismatch, &iss)
+	}
+}
+
+// PrintAnalyzerResult adds the errors and the metrics of the analyzer to the
+// report, so that a run failed by the errors policy is explained by it.
+func (p *JSONIssuePrinter) PrintAnalyzerResult(errors []string, metrics []*Metric) {
+	p.report.Checks.Errors = append(p.report.Checks.Errors, errors...)
+	p.report.Checks.Metrics = append(p.report.Checks.Metrics, metrics...)
+}
+
+func (p *JSONIssuePrinter) PrintUnifiedDiff(file string, diff gotextdiff.Unified) {
+	d := &jsonAutofixDiff{
+		Unified: fmt.Sprint(diff),
+		Hunks:   make([]*jsonHunk, 0, len(diff.Hunks)),
+	}
+
+	for _, hunk := range diff.Hunks {
+		h := &jsonHunk{
+			FromLine: hunk.FromLine,
+			ToLine:   hunk.ToLine,
+			Lines:    make([]*jsonHunkLine, 0, len(hunk.Lines)),
+		}
+		for _, l := range hunk.Lines {
+			h.Lines = append(h.Lines, &jsonHunkLine{Kind: l.Kind.String(), Content: l.Content})
+		}
+		d.Hunks = append(d.Hunks, h)
+	}
+
+	p.report.Autofix.Diffs[relativePath(p.cwd, file)] = d
+}
+
+func (p *JSONIssuePrinter) PrintIdenticalGoldenFile(file string) {
+	p.report.Autofix.Identical = append(p.report.Autofix.Identical, relativePath(p.cwd, file))
+}
+
+func (p *JSONIssuePrinter) PrintStatus(passed bool) {
+	p.report.Passed = p.report.Passed && passed
+}
+
+func (p *JSONIssuePrinter) PrintWarning(warning string) {
+	p.report.Warnings = append(p.report.Warnings, warning)
+}
+
+// PrintError fails the report with the error which stopped the run.
+func (p *JSONIssuePrinter) PrintError(err error) {
+	p.report.Passed = false
+	p.report.Error = err.Error()
+}
+
+func (*JSONIssuePrinter) PrintSuiteHeader(string) {}
+
+// PrintSummary adds the results of the suites to the report.
+func (p *JSONIssuePrinter) PrintSummary(results []*SuiteResult) {
+	for _, result := range results {
+		suite := &jsonSuite{
+			Suite:    result.Suite,
+			Passed:   result.Passed,
+			Duration: result.Duration.Seconds(),
+		}
+		if result.Err != nil {
+			suite.Error = result.Err.Error()
+		}
+
+		p.report.Suites = append(p.report.Suites, suite)
+		p.report.Passed = p.report.Passed && result.Passed
+	}
+}
+
+// Flush writes the JSON report to the underlying writer.
+func (p *JSONIssuePrinter) Flush() error {
+	for _, issues := range p.report.Checks.Files {
+		sortIssues(issues.Unexpected)
+		sortIssues(issues.NotRaised)
+		sortIssues(issues.EndMismatch)
+		sortIssues(issues.Forbidden)
+		sortIssues(issues.CountMismatch)
+	}
+	sort.Strings(p.report.Autofix.Identical)
+
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p.report)
+}
+
+// sortIssues sorts the issues by their position and code, so that the order of
+// the issues in a report is stable.
+func sortIssues(issues []*FailedIssue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i].Position.Start, issues[j].Position.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return issues[i].Code < issues[j].Code
+	})
+}
@@ -0,0 +1,187 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+)
+
+// TestJSONIssuePrinter tests the generated JSON report
+func TestJSONIssuePrinter(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mainFile := filepath.Join(cwd, "main.go")
+
+	buf := &bytes.Buffer{}
+	printer := NewJSONIssuePrinter(buf)
+
+	printer.PrintHeader("Testing checks")
+	printer.PrintIssue(mainFile, 9, 0, IssueNotRaised, &FailedIssue{Issue: &Issue{
+		Code:     "VET-V0002",
+		Position: IssuePosition{Start: Location{Line: 9}},
+	}})
+	printer.PrintIssue(mainFile, 4, 9, IssueUnexpected, &FailedIssue{Issue: &Issue{
+		Code:     "GO-C5001",
+		Title:    "Redundant type",
+		Position: IssuePosition{File: "main.go", Start: Location{Line: 4, Column: 9}},
+	}})
+	printer.PrintWarning("warning")
+	printer.PrintAnalyzerResult(
+		[]string{"Unable to parse foo.go"},
+		[]*Metric{{Code: "NLCV", Namespace: "Go", Value: 42}},
+	)
+
+	printer.PrintHeader("Testing Autofix")
+	edits := myers.ComputeEdits(span.URIFromPath("main.go"), "a\nb\n", "a\nc\n")
+	printer.PrintUnifiedDiff(mainFile, gotextdiff.ToUnified("main.go", "main.go.golden", "a\nb\n", edits))
+	printer.PrintIdenticalGoldenFile(filepath.Join(cwd, "foo.go"))
+	printer.PrintStatus(false)
+
+	if err := printer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{
+  "passed": false,
+  "checks": {
+    "files": {
+      "main.go": {
+        "unexpected": [
+          {
+            "code": "GO-C5001",
+            "title": "Redundant type",
+            "position": {
+              "file": "main.go",
+              "start": {"line": 4, "column": 9},
+              "end": null
+            }
+          }
+        ],
+        "not-raised": [
+          {
+            "code": "VET-V0002",
+            "title": "",
+            "position": {
+              "file": "main.go",
+              "start": {"line": 9, "column": 0},
+              "end": null
+            }
+          }
+        ]
+      }
+    },
+    "errors": ["Unable to parse foo.go"],
+    "metrics": [{"code": "NLCV", "namespace": "Go", "value": 42}]
+  },
+  "autofix": {
+    "diffs": {
+      "main.go": {
+        "unified": "--- main.go\n+++ main.go.golden\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n",
+        "hunks": [
+          {
+            "from_line": 1,
+            "to_line": 1,
+            "lines": [
+              {"kind": "equal", "content": "a\n"},
+              {"kind": "delete", "content": "b\n"},
+              {"kind": "insert", "content": "c\n"}
+            ]
+          }
+        ]
+      }
+    },
+    "identical": ["foo.go"]
+  },
+  "warnings": ["warning"]
+}`
+
+	var got, want any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		t.Fatal(err)
+	}
+
+	if !cmp.Equal(got, want) {
+		t.Fatalf("unexpected report, diff: %s", cmp.Diff(want, got))
+	}
+}
+
+// TestJSONIssuePrinter_Order checks that the failures of each kind are sorted
+// by their position and code, regardless of the order they are printed in.
+func TestJSONIssuePrinter_Order(t *testing.T) {
+	printer := NewJSONIssuePrinter(io.Discard)
+
+	failureTypes := []int{IssueNotRaised, IssueEndMismatch, IssueForbidden, IssueCountMismatch}
+	for _, failureType := range failureTypes {
+		for _, code := range []string{"GO-W1002", "GO-W1000", "GO-W1001"} {
+			printer.PrintIssue("main.go", 3, 0, failureType, &FailedIssue{Issue: &Issue{
+				Code:     code,
+				Position: IssuePosition{Start: Location{Line: 3}},
+			}})
+		}
+	}
+
+	if err := printer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := printer.report.Checks.Files["main.go"]
+	lists := map[string][]*FailedIssue{
+		"not-raised":     issues.NotRaised,
+		"end-mismatch":   issues.EndMismatch,
+		"forbidden":      issues.Forbidden,
+		"count-mismatch": issues.CountMismatch,
+	}
+
+	expected := []string{"GO-W1000", "GO-W1001", "GO-W1002"}
+	for name, list := range lists {
+		codes := make([]string, 0, len(list))
+		for _, iss := range list {
+			codes = append(codes, iss.Code)
+		}
+
+		if !cmp.Equal(codes, expected) {
+			t.Errorf("unexpected order of %s, diff: %s", name, cmp.Diff(expected, codes))
+		}
+	}
+}
+
+// TestJSONIssuePrinter_Error checks that the error stopping the run fails the
+// report.
+func TestJSONIssuePrinter_Error(t *testing.T) {
+	buf := &bytes.Buffer{}
+	printer := NewJSONIssuePrinter(buf)
+
+	printer.PrintHeader("Testing checks")
+	printer.PrintError(errors.New("checks script exited with code 1"))
+
+	if err := printer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Passed bool   `json:"passed"`
+		Error  string `json:"error"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Passed || got.Error != "checks script exited with code 1" {
+		t.Errorf("unexpected report, passed %v, error %q", got.Passed, got.Error)
+	}
+}
@@ -0,0 +1,251 @@
+package runner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/hexops/gotextdiff"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Time     string            `xml:"time,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Time      string           `xml:"time,attr"`
+	TestCases []*junitTestCase `xml:"testcase"`
+	SystemOut string           `xml:"system-out,omitempty"`
+
+	startTime time.Time
+	duration  time.Duration
+	cases     map[string]*junitTestCase
+}
+
+type junitTestCase struct {
+	Name      string          `xml:"name,attr"`
+	ClassName string          `xml:"classname,attr"`
+	Failures  []*junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+// JUnitIssuePrinter collects the test results and writes them as a JUnit XML
+// report when flushed. Each header printed by the runner becomes a test suite,
+// and each tested file becomes a test case in it.
+type JUnitIssuePrinter struct {
+	w   io.Writer
+	cwd string
+
+	suites  []*junitTestSuite
+	current *junitTestSuite
+
+	// suitePrefix is the SCATR suite the results belong to, when running
+	// multiple suites.
+	suitePrefix string
+
+	// replayTime is the time the replayed calls were made, see
+	// replayTimePrinter.
+	replayTime time.Time
+}
+
+func NewJUnitIssuePrinter(w io.Writer) *JUnitIssuePrinter {
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = ""
+	}
+
+	return &JUnitIssuePrinter{w: w, cwd: cwd}
+}
+
+// suite returns the test suite currently being printed. Results printed before
+// any header are grouped in a test suite named "scatr".
+func (p *JUnitIssuePrinter) suite() *junitTestSuite {
+	if p.current == nil {
+		p.PrintHeader("scatr")
+	}
+
+	return p.current
+}
+
+func (p *JUnitIssuePrinter) testCase(file string) *junitTestCase {
+	suite := p.suite()
+
+	name := relativePath(p.cwd, file)
+	tc, ok := suite.cases[name]
+	if !ok {
+		tc = &junitTestCase{
+			Name:      name,
+			ClassName: suite.Name,
+			Failures:  []*junitFailure{},
+		}
+		suite.cases[name] = tc
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	return tc
+}
+
+func (p *JUnitIssuePrinter) addFailure(file string, failure *junitFailure) {
+	tc := p.testCase(file)
+	tc.Failures = append(tc.Failures, failure)
+}
+
+func (p *JUnitIssuePrinter) setReplayTime(t time.Time) {
+	p.replayTime = t
+}
+
+// now returns the time of the call being printed.
+func (p *JUnitIssuePrinter) now() time.Time {
+	if !p.replayTime.IsZero() {
+		return p.replayTime
+	}
+
+	return time.Now()
+}
+
+func (p *JUnitIssuePrinter) endSuite() {
+	if p.current != nil && p.current.duration == 0 {
+		p.current.duration = p.now().Sub(p.current.startTime)
+	}
+}
+
+func (p *JUnitIssuePrinter) PrintHeader(header string) {
+	p.endSuite()
+
+	name := header
+	if p.suitePrefix != "" {
+		name = p.suitePrefix + ": " + header
+	}
+
+	p.current = &junitTestSuite{
+		Name:      name,
+		TestCases: []*junitTestCase{},
+		startTime: p.now(),
+		cases:     make(map[string]*junitTestCase),
+	}
+	p.suites = append(p.suites, p.current)
+}
+
+// PrintTestedFiles adds a test case for each tested file, so that the files
+// which passed are present in the report as well.
+func (p *JUnitIssuePrinter) PrintTestedFiles(files []string) {
+	for _, file := range files {
+		p.testCase(file)
+	}
+}
+
+func (p *JUnitIssuePrinter) PrintIssue(file string, line, column, failureType int, issue *FailedIssue) {
+	position := relativePath(p.cwd, file) + ":" + formatPosition(line, column)
+	p.addFailure(file, &junitFailure{
+		Message:  getIssueTypeString(failureType) + ": " + formatIssue(failureType, issue),
+		Type:     getIssueTypeString(failureType),
+		Contents: position + " " + formatIssue(failureType, issue),
+	})
+}
+
+func (p *JUnitIssuePrinter) PrintUnifiedDiff(file string, diff gotextdiff.Unified) {
+	for _, hunk := range diff.Hunks {
+		p.addFailure(file, &junitFailure{
+			Message:  fmt.Sprintf("Autofix result differs from the golden file at line %d", hunk.FromLine),
+			Type:     "Autofix mismatch",
+			Contents: hunkString(diff, hunk),
+		})
+	}
+}
+
+func (p *JUnitIssuePrinter) PrintIdenticalGoldenFile(file string) {
+	p.addFailure(file, &junitFailure{
+		Message: "Input file identical to the golden file",
+		Type:    "Identical golden file",
+	})
+}
+
+func (p *JUnitIssuePrinter) PrintWarning(warning string) {
+	suite := p.suite()
+	suite.SystemOut += "Warn: " + warning + "\n"
+}
+
+// PrintError adds the error which stopped the run as a failure of the "scatr"
+// test case.
+func (p *JUnitIssuePrinter) PrintError(err error) {
+	p.addFailure("scatr", &junitFailure{
+		Message: err.Error(),
+		Type:    "Error",
+	})
+}
+
+func (p *JUnitIssuePrinter) PrintStatus(bool) {
+	p.endSuite()
+}
+
+// PrintSuiteHeader prefixes the names of the following test suites with the
+// SCATR suite.
+func (p *JUnitIssuePrinter) PrintSuiteHeader(suite string) {
+	p.endSuite()
+	p.current = nil
+	p.suitePrefix = suite
+}
+
+func (*JUnitIssuePrinter) PrintSummary([]*SuiteResult) {}
+
+// Flush writes the JUnit XML report to the underlying writer.
+func (p *JUnitIssuePrinter) Flush() error {
+	p.endSuite()
+
+	report := &junitTestSuites{Suites: p.suites}
+
+	var total time.Duration
+	for _, suite := range p.suites {
+		sort.SliceStable(suite.TestCases, func(i, j int) bool {
+			return suite.TestCases[i].Name < suite.TestCases[j].Name
+		})
+
+		suite.Tests = len(suite.TestCases)
+		suite.Failures = 0
+		for _, tc := range suite.TestCases {
+			if len(tc.Failures) != 0 {
+				suite.Failures++
+			}
+		}
+		suite.Time = formatSeconds(suite.duration)
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		total += suite.duration
+	}
+	report.Time = formatSeconds(total)
+
+	_, err := io.WriteString(p.w, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(p.w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(report)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(p.w, "\n")
+	return err
+}
+
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,157 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+)
+
+// TestJUnitIssuePrinter tests the structure of the generated JUnit report
+func TestJUnitIssuePrinter(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mainFile := filepath.Join(cwd, "main.go")
+	fooFile := filepath.Join(cwd, "foo.go")
+
+	buf := &bytes.Buffer{}
+	printer := NewJUnitIssuePrinter(buf)
+
+	printer.PrintHeader("Testing checks")
+	printer.PrintTestedFiles([]string{fooFile, mainFile})
+	printer.PrintIssue(mainFile, 4, 9, IssueUnexpected, &FailedIssue{Issue: &Issue{Code: "GO-C5001", Title: "Redundant type"}})
+	printer.PrintIssue(mainFile, 9, 0, IssueNotRaised, &FailedIssue{Issue: &Issue{Code: "VET-V0002"}})
+	printer.PrintWarning(`"bar.go" is present in the analysis result but is not checked by SCATR.`)
+
+	printer.PrintHeader("Testing Autofix")
+	printer.PrintTestedFiles([]string{mainFile})
+	edits := myers.ComputeEdits(span.URIFromPath("main.go"), "a\nb\n", "a\nc\n")
+	printer.PrintUnifiedDiff(mainFile, gotextdiff.ToUnified("main.go", "main.go.golden", "a\nb\n", edits))
+	printer.PrintStatus(false)
+
+	if err := printer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Fatalf("report does not start with the XML header: %s", buf.String())
+	}
+
+	var got junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	type testCase struct {
+		Name     string
+		Failures []string
+	}
+	type testSuite struct {
+		Name      string
+		Tests     int
+		Failures  int
+		TestCases []testCase
+		SystemOut string
+	}
+
+	gotSuites := []testSuite{}
+	for _, suite := range got.Suites {
+		s := testSuite{
+			Name:      suite.Name,
+			Tests:     suite.Tests,
+			Failures:  suite.Failures,
+			TestCases: []testCase{},
+			SystemOut: suite.SystemOut,
+		}
+		for _, tc := range suite.TestCases {
+			c := testCase{Name: tc.Name, Failures: []string{}}
+			for _, f := range tc.Failures {
+				c.Failures = append(c.Failures, f.Message)
+			}
+			s.TestCases = append(s.TestCases, c)
+		}
+		gotSuites = append(gotSuites, s)
+	}
+
+	expected := []testSuite{
+		{
+			Name:     "Testing checks",
+			Tests:    2,
+			Failures: 1,
+			TestCases: []testCase{
+				{Name: "foo.go", Failures: []string{}},
+				{Name: "main.go", Failures: []string{
+					`Unexpected Issue: GO-C5001: "Redundant type"`,
+					`Issue not raised: VET-V0002: ""`,
+				}},
+			},
+			SystemOut: "Warn: \"bar.go\" is present in the analysis result but is not checked by SCATR.\n",
+		},
+		{
+			Name:     "Testing Autofix",
+			Tests:    1,
+			Failures: 1,
+			TestCases: []testCase{
+				{Name: "main.go", Failures: []string{
+					"Autofix result differs from the golden file at line 1",
+				}},
+			},
+		},
+	}
+
+	if !cmp.Equal(gotSuites, expected) {
+		t.Fatalf("unexpected report, diff: %s", cmp.Diff(expected, gotSuites))
+	}
+
+	if got.Tests != 3 || got.Failures != 2 {
+		t.Fatalf("expected 3 tests and 2 failures, got %d tests and %d failures", got.Tests, got.Failures)
+	}
+}
+
+// TestJUnitIssuePrinter_Replay tests that the durations of the test suites of a
+// suite run in parallel are the ones of the run, and not of the replay.
+func TestJUnitIssuePrinter_Replay(t *testing.T) {
+	buffered := &bufferedIssuePrinter{}
+	buffered.PrintHeader("Testing checks")
+	time.Sleep(20 * time.Millisecond)
+	buffered.PrintStatus(true)
+
+	buf := &bytes.Buffer{}
+	printer := NewJUnitIssuePrinter(buf)
+	printer.PrintSuiteHeader("suite")
+	buffered.replay(MultiIssuePrinter{printer})
+
+	if err := printer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Suites) != 1 {
+		t.Fatalf("expected 1 test suite, got %d", len(got.Suites))
+	}
+
+	duration, err := strconv.ParseFloat(got.Suites[0].Time, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if duration < 0.02 || got.Time != got.Suites[0].Time {
+		t.Fatalf("unexpected durations, suite: %s, total: %s", got.Suites[0].Time, got.Time)
+	}
+}
@@ -0,0 +1,342 @@
+package runner
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hexops/gotextdiff"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	sarifBaseID  = "%SRCROOT%"
+
+	sarifRuleAutofixMismatch     = "scatr/autofix-mismatch"
+	sarifRuleIdenticalGoldenFile = "scatr/identical-golden-file"
+)
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                         `json:"tool"`
+	Invocations        []*sarifInvocation                `json:"invocations"`
+	OriginalURIBaseIDs map[string]*sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []*sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	InformationURI string       `json:"informationUri"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                 `json:"executionSuccessful"`
+	ToolExecutionNotifications []*sarifNotification `json:"toolExecutionNotifications"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	RuleIndex  int               `json:"ruleIndex"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []*sarifLocation  `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// SARIFIssuePrinter collects the test results and writes them as a SARIF 2.1.0
+// log when flushed. Each unexpected issue, issue not raised and Autofix diff
+// hunk is reported as a result on the affected file.
+type SARIFIssuePrinter struct {
+	w   io.Writer
+	cwd string
+
+	rules map[string]int
+	run   *sarifRun
+
+	// notifications is the warnings, along with the error stopping the run, if
+	// any, in which case failed is set.
+	notifications []*sarifNotification
+	failed        bool
+}
+
+func NewSARIFIssuePrinter(w io.Writer) *SARIFIssuePrinter {
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = ""
+	}
+
+	run := &sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "SCATR",
+			InformationURI: "https://github.com/deepsourcelabs/SCATR",
+			Rules:          []*sarifRule{},
+		}},
+		Results: []*sarifResult{},
+	}
+
+	if cwd != "" {
+		run.OriginalURIBaseIDs = map[string]*sarifArtifactLocation{
+			sarifBaseID: {URI: fileURI(cwd) + "/"},
+		}
+	}
+
+	return &SARIFIssuePrinter{
+		w:             w,
+		cwd:           cwd,
+		rules:         make(map[string]int),
+		run:           run,
+		notifications: []*sarifNotification{},
+	}
+}
+
+// ruleIndex returns the index of the rule with the provided ID, adding it to
+// the driver's rules if required.
+func (p *SARIFIssuePrinter) ruleIndex(id, description string) int {
+	if idx, ok := p.rules[id]; ok {
+		return idx
+	}
+
+	idx := len(p.run.Tool.Driver.Rules)
+	p.run.Tool.Driver.Rules = append(p.run.Tool.Driver.Rules, &sarifRule{
+		ID:               id,
+		ShortDescription: sarifMessage{Text: description},
+	})
+	p.rules[id] = idx
+
+	return idx
+}
+
+func (p *SARIFIssuePrinter) location(file string, region *sarifRegion) *sarifLocation {
+	loc := &sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{Region: region},
+	}
+
+	rel := relativePath(p.cwd, file)
+	if filepath.IsAbs(rel) {
+		loc.PhysicalLocation.ArtifactLocation.URI = fileURI(rel)
+	} else {
+		loc.PhysicalLocation.ArtifactLocation.URI = filepath.ToSlash(rel)
+		loc.PhysicalLocation.ArtifactLocation.URIBaseID = sarifBaseID
+	}
+
+	return loc
+}
+
+func (*SARIFIssuePrinter) PrintHeader(string) {}
+
+func (p *SARIFIssuePrinter) PrintIssue(file string, line, column, failureType int, issue *FailedIssue) {
+	var region *sarifRegion
+	if line > 0 {
+		region = &sarifRegion{StartLine: line, StartColumn: column}
+		if end := issue.Position.End; end != nil && end.Line >= line {
+			region.EndLine = end.Line
+			// The end column is exclusive in SARIF.
+			if end.Column > 0 {
+				region.EndColumn = end.Column + 1
+			}
+		}
+	}
+
+	kind := "unexpected"
+	switch failureType {
+	case IssueNotRaised:
+		kind = "not-raised"
+	case IssueEndMismatch:
+		kind = "end-mismatch"
+	case IssueForbidden:
+		kind = "forbidden"
+	case IssueCountMismatch:
+		kind = "count-mismatch"
+	}
+
+	text := getIssueTypeString(failureType)
+	if title := issueTitle(issue); title != "" {
+		text += ": " + title
+	}
+	if detail := getIssueDetail(failureType, issue); detail != "" {
+		text += " (" + detail + ")"
+	}
+
+	p.run.Results = append(p.run.Results, &sarifResult{
+		RuleID:     issue.Code,
+		RuleIndex:  p.ruleIndex(issue.Code, issue.Code),
+		Level:      "error",
+		Message:    sarifMessage{Text: text},
+		Locations:  []*sarifLocation{p.location(file, region)},
+		Properties: map[string]string{"scatr-kind": kind},
+	})
+}
+
+func (p *SARIFIssuePrinter) PrintUnifiedDiff(file string, diff gotextdiff.Unified) {
+	idx := p.ruleIndex(sarifRuleAutofixMismatch, "Autofix result differs from the golden file")
+
+	for _, hunk := range diff.Hunks {
+		fromLines := 0
+		for _, l := range hunk.Lines {
+			if l.Kind != gotextdiff.Insert {
+				fromLines++
+			}
+		}
+
+		region := &sarifRegion{StartLine: hunk.FromLine}
+		if hunk.FromLine == 0 {
+			region.StartLine = 1
+		}
+		if fromLines > 1 {
+			region.EndLine = region.StartLine + fromLines - 1
+		}
+
+		p.run.Results = append(p.run.Results, &sarifResult{
+			RuleID:     sarifRuleAutofixMismatch,
+			RuleIndex:  idx,
+			Level:      "error",
+			Message:    sarifMessage{Text: "Autofix result differs from the golden file:\n" + hunkString(diff, hunk)},
+			Locations:  []*sarifLocation{p.location(file, region)},
+			Properties: map[string]string{"scatr-kind": "autofix-mismatch"},
+		})
+	}
+}
+
+func (p *SARIFIssuePrinter) PrintIdenticalGoldenFile(file string) {
+	idx := p.ruleIndex(sarifRuleIdenticalGoldenFile, "Input file identical to the golden file")
+
+	p.run.Results = append(p.run.Results, &sarifResult{
+		RuleID:     sarifRuleIdenticalGoldenFile,
+		RuleIndex:  idx,
+		Level:      "error",
+		Message:    sarifMessage{Text: "Input file identical to the golden file"},
+		Locations:  []*sarifLocation{p.location(file, nil)},
+		Properties: map[string]string{"scatr-kind": "identical-golden-file"},
+	})
+}
+
+func (*SARIFIssuePrinter) PrintStatus(bool) {}
+
+// PrintError records the error which stopped the run as an error notification
+// of an unsuccessful invocation.
+func (p *SARIFIssuePrinter) PrintError(err error) {
+	p.failed = true
+	p.notifications = append(p.notifications, &sarifNotification{
+		Level:   "error",
+		Message: sarifMessage{Text: err.Error()},
+	})
+}
+
+func (p *SARIFIssuePrinter) PrintWarning(warning string) {
+	p.notifications = append(p.notifications, &sarifNotification{
+		Level:   "warning",
+		Message: sarifMessage{Text: warning},
+	})
+}
+
+// Flush writes the SARIF log to the underlying writer.
+func (p *SARIFIssuePrinter) Flush() error {
+	// Sort the rules so that the log is stable across runs.
+	rules := p.run.Tool.Driver.Rules
+	sort.SliceStable(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	for i, rule := range rules {
+		p.rules[rule.ID] = i
+	}
+	for _, result := range p.run.Results {
+		result.RuleIndex = p.rules[result.RuleID]
+	}
+
+	sort.SliceStable(p.run.Results, func(i, j int) bool {
+		a, b := p.run.Results[i], p.run.Results[j]
+		aLoc, bLoc := a.Locations[0].PhysicalLocation, b.Locations[0].PhysicalLocation
+
+		if aLoc.ArtifactLocation.URI != bLoc.ArtifactLocation.URI {
+			return aLoc.ArtifactLocation.URI < bLoc.ArtifactLocation.URI
+		}
+
+		var aLine, bLine, aCol, bCol int
+		if aLoc.Region != nil {
+			aLine, aCol = aLoc.Region.StartLine, aLoc.Region.StartColumn
+		}
+		if bLoc.Region != nil {
+			bLine, bCol = bLoc.Region.StartLine, bLoc.Region.StartColumn
+		}
+
+		if aLine != bLine {
+			return aLine < bLine
+		}
+		if aCol != bCol {
+			return aCol < bCol
+		}
+		return a.RuleID < b.RuleID
+	})
+
+	p.run.Invocations = []*sarifInvocation{{
+		ExecutionSuccessful:        !p.failed,
+		ToolExecutionNotifications: p.notifications,
+	}}
+
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(&sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []*sarifRun{p.run},
+	})
+}
+
+// fileURI returns the file:// URI for an absolute file path.
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows paths like C:/foo need a leading slash in the URI.
+		path = "/" + path
+	}
+
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,100 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+)
+
+// TestSARIFIssuePrinter tests the results and rules of the generated SARIF log
+func TestSARIFIssuePrinter(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mainFile := filepath.Join(cwd, "main.go")
+
+	buf := &bytes.Buffer{}
+	printer := NewSARIFIssuePrinter(buf)
+
+	printer.PrintIssue(mainFile, 9, 0, IssueNotRaised, &FailedIssue{Issue: &Issue{Code: "VET-V0002"}})
+	printer.PrintIssue(mainFile, 4, 9, IssueUnexpected, &FailedIssue{Issue: &Issue{
+		Code:  "GO-C5001",
+		Title: "Redundant type",
+		Position: IssuePosition{
+			Start: Location{Line: 4, Column: 9},
+			End:   &Location{Line: 4, Column: 12},
+		},
+	}})
+	edits := myers.ComputeEdits(span.URIFromPath("main.go"), "a\nb\n", "a\nc\n")
+	printer.PrintUnifiedDiff(mainFile, gotextdiff.ToUnified("main.go", "main.go.golden", "a\nb\n", edits))
+	printer.PrintWarning("warning")
+	printer.PrintStatus(false)
+
+	if err := printer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Version != sarifVersion || len(got.Runs) != 1 {
+		t.Fatalf("unexpected SARIF version %q or number of runs %d", got.Version, len(got.Runs))
+	}
+	run := got.Runs[0]
+
+	ruleIDs := []string{}
+	for _, rule := range run.Tool.Driver.Rules {
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+	expectedRuleIDs := []string{"GO-C5001", "VET-V0002", sarifRuleAutofixMismatch}
+	if !cmp.Equal(ruleIDs, expectedRuleIDs) {
+		t.Fatalf("unexpected rules, diff: %s", cmp.Diff(expectedRuleIDs, ruleIDs))
+	}
+
+	type result struct {
+		RuleID    string
+		RuleIndex int
+		Kind      string
+		Region    sarifRegion
+	}
+
+	results := []result{}
+	for _, r := range run.Results {
+		loc := r.Locations[0].PhysicalLocation
+		if loc.ArtifactLocation.URI != "main.go" || loc.ArtifactLocation.URIBaseID != sarifBaseID {
+			t.Fatalf("unexpected artifact location %+v", loc.ArtifactLocation)
+		}
+
+		results = append(results, result{
+			RuleID:    r.RuleID,
+			RuleIndex: r.RuleIndex,
+			Kind:      r.Properties["scatr-kind"],
+			Region:    *loc.Region,
+		})
+	}
+
+	expectedResults := []result{
+		{RuleID: sarifRuleAutofixMismatch, RuleIndex: 2, Kind: "autofix-mismatch", Region: sarifRegion{StartLine: 1, EndLine: 2}},
+		{RuleID: "GO-C5001", RuleIndex: 0, Kind: "unexpected", Region: sarifRegion{StartLine: 4, StartColumn: 9, EndLine: 4, EndColumn: 13}},
+		{RuleID: "VET-V0002", RuleIndex: 1, Kind: "not-raised", Region: sarifRegion{StartLine: 9}},
+	}
+	if !cmp.Equal(results, expectedResults) {
+		t.Fatalf("unexpected results, diff: %s", cmp.Diff(expectedResults, results))
+	}
+
+	notifications := run.Invocations[0].ToolExecutionNotifications
+	if len(notifications) != 1 || notifications[0].Message.Text != "warning" {
+		t.Fatalf("unexpected notifications %+v", notifications)
+	}
+}
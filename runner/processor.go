@@ -2,37 +2,139 @@ package runner
 
 import (
 	"bytes"
-	"log"
+	"context"
+	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 )
 
-func runProcessor(cfg ProcessorConfig, filePath, codePath string) (*Result, error) {
+const (
+	processorFormatSARIF      = "sarif"
+	processorFormatCheckstyle = "checkstyle"
+	processorFormatRegex      = "regex"
+)
+
+// The policies for the problems found while processing the output, like the
+// errors reported by the analyzer.
+const (
+	policyIgnore = "ignore"
+	policyWarn   = "warn"
+	policyFail   = "fail"
+)
+
+// runProcessor processes the output file of the checks script, which is passed
+// to the processor script in the directory of the config using INPUT_FILE. In
+// case a format is configured, the built-in processor for it is used instead.
+func runProcessor(ctx context.Context, config *Config, filePath string) (*Result, error) {
+	logger := loggerFrom(ctx)
+	cfg := config.Processor
+	codePath := config.resolvePath(config.CodePath)
+
+	if cfg.Format != "" {
+		logger.Printf("Processing the test script output using the built-in %q processor\n", cfg.Format)
+
+		b, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		var res *Result
+		switch cfg.Format {
+		case processorFormatSARIF:
+			res, err = processSARIF(b)
+		case processorFormatCheckstyle:
+			res, err = processCheckstyle(b, cfg.SourcePrefix)
+		case processorFormatRegex:
+			res, err = processRegex(b, cfg.Pattern, cfg.Unmatched)
+		default:
+			return nil, fmt.Errorf("unsupported processor format %q", cfg.Format)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to process %s as %s: %w", filePath, cfg.Format, err)
+		}
+
+		normalizeResult(ctx, res, codePath)
+		return res, nil
+	}
+
 	if cfg.SkipProcessing {
-		log.Println("Skipping processing of the test script output")
+		logger.Println("Skipping processing of the test script output")
 
 		b, err := os.ReadFile(filePath)
 		if err != nil {
 			return nil, err
 		}
-		return unmarshalResult(b, codePath)
+		return unmarshalResult(ctx, b, codePath)
 	}
 
-	log.Printf("Processing the test script output using %q\n", cfg.Interpreter)
+	logger.Printf("Processing the test script output using %q\n", cfg.Interpreter)
 
 	cmd := exec.Command(cfg.Interpreter)
-	cmd.Env = append(os.Environ(), "INPUT_FILE="+filePath)
+	cmd.Env = scriptEnv(config.CleanEnv, cfg.Env, map[string]string{"INPUT_FILE": filePath})
+	cmd.Dir = config.Dir
 
 	buf := &bytes.Buffer{}
 	cmd.Stdin = strings.NewReader(cfg.Script)
 	cmd.Stdout = buf
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = scriptOutputFrom(ctx)
+	setProcessGroup(cmd)
 
-	err := cmd.Run()
+	err := runCommand(ctx, cmd, stageProcessor, cfg.Timeout)
 	if err != nil {
 		return nil, err
 	}
 
-	return unmarshalResult(buf.Bytes(), codePath)
+	return unmarshalResult(ctx, buf.Bytes(), codePath)
+}
+
+// validatePolicy checks that the policy configured using the key is supported.
+// An empty policy uses the default of the key.
+func validatePolicy(key, policy string) error {
+	switch policy {
+	case "", policyIgnore, policyWarn, policyFail:
+		return nil
+	default:
+		return fmt.Errorf("unsupported %s policy %q, expected one of ignore, warn or fail", key, policy)
+	}
+}
+
+// validate checks the format and the policies of the processor, so that an
+// unsupported one is reported before running the checks.
+func (c *ProcessorConfig) validate() error {
+	switch c.Format {
+	case "", processorFormatSARIF, processorFormatCheckstyle, processorFormatRegex:
+	default:
+		return fmt.Errorf(
+			"unsupported processor format %q, expected one of sarif, checkstyle or regex", c.Format,
+		)
+	}
+
+	if err := validatePolicy("errors", c.Errors); err != nil {
+		return err
+	}
+
+	return validatePolicy("unmatched", c.Unmatched)
+}
+
+// applyErrorsPolicy reports the errors of the analyzer in the result as per the
+// policy, which warns about them by default.
+func applyErrorsPolicy(res *Result, policy string) error {
+	if err := validatePolicy("errors", policy); err != nil {
+		return err
+	}
+
+	if policy == policyIgnore {
+		return nil
+	}
+
+	for _, msg := range res.Errors {
+		res.warnings = append(res.warnings, "The analyzer reported an error: "+msg)
+	}
+
+	if policy == policyFail && len(res.Errors) > 0 {
+		res.failed = true
+	}
+
+	return nil
 }
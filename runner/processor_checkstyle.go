@@ -0,0 +1,50 @@
+package runner
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+type checkstyleResult struct {
+	Files []*checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string             `xml:"name,attr"`
+	Errors []*checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line    int    `xml:"line,attr"`
+	Column  int    `xml:"column,attr"`
+	Message string `xml:"message,attr"`
+	Source  string `xml:"source,attr"`
+}
+
+// processCheckstyle converts a Checkstyle XML result to a Result. The issue
+// code is the source of an error without the sourcePrefix, and its title is
+// the message. The file names are either absolute, or relative to the code
+// path.
+func processCheckstyle(b []byte, sourcePrefix string) (*Result, error) {
+	var checkstyle checkstyleResult
+	if err := xml.Unmarshal(b, &checkstyle); err != nil {
+		return nil, err
+	}
+
+	res := &Result{Issues: []*Issue{}}
+
+	for _, file := range checkstyle.Files {
+		for _, e := range file.Errors {
+			res.Issues = append(res.Issues, &Issue{
+				Code:  strings.TrimPrefix(e.Source, sourcePrefix),
+				Title: e.Message,
+				Position: IssuePosition{
+					File:  file.Name,
+					Start: Location{Line: e.Line, Column: e.Column},
+				},
+			})
+		}
+	}
+
+	return res, nil
+}
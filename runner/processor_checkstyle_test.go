@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestProcessCheckstyle(t *testing.T) {
+	tests := []struct {
+		name         string
+		xml          string
+		sourcePrefix string
+		want         []*Issue
+		wantErr      bool
+	}{
+		{
+			name: "multiple files",
+			xml: `<?xml version="1.0" encoding="utf-8"?>
+<checkstyle version="4.3">
+  <file name="/code/index.js">
+    <error line="3" column="7" severity="error" message="'a' is assigned a value but never used." source="eslint.rules.no-unused-vars" />
+    <error line="5" severity="warning" message="Unexpected console statement." source="eslint.rules.no-console" />
+  </file>
+  <file name="lib/util.js">
+    <error line="1" column="1" severity="error" message="Missing &quot;use strict&quot; statement." source="eslint.rules.strict" />
+  </file>
+  <file name="clean.js"></file>
+</checkstyle>`,
+			sourcePrefix: "eslint.rules.",
+			want: []*Issue{
+				{
+					Code:     "no-unused-vars",
+					Title:    "'a' is assigned a value but never used.",
+					Position: IssuePosition{File: "/code/index.js", Start: Location{Line: 3, Column: 7}},
+				},
+				{
+					Code:     "no-console",
+					Title:    "Unexpected console statement.",
+					Position: IssuePosition{File: "/code/index.js", Start: Location{Line: 5}},
+				},
+				{
+					Code:     "strict",
+					Title:    `Missing "use strict" statement.`,
+					Position: IssuePosition{File: "lib/util.js", Start: Location{Line: 1, Column: 1}},
+				},
+			},
+		},
+		{
+			name: "source without the prefix",
+			xml: `<checkstyle>
+  <file name="Main.java">
+    <error line="2" message="Line is longer than 100 characters." source="com.puppycrawl.tools.checkstyle.checks.sizes.LineLengthCheck" />
+  </file>
+</checkstyle>`,
+			sourcePrefix: "eslint.rules.",
+			want: []*Issue{
+				{
+					Code:     "com.puppycrawl.tools.checkstyle.checks.sizes.LineLengthCheck",
+					Title:    "Line is longer than 100 characters.",
+					Position: IssuePosition{File: "Main.java", Start: Location{Line: 2}},
+				},
+			},
+		},
+		{
+			name:    "invalid XML",
+			xml:     `<checkstyle><file name="main.go">`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := processCheckstyle([]byte(tt.xml), tt.sourcePrefix)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("processCheckstyle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			opts := cmpopts.IgnoreUnexported(IssuePosition{})
+			if !cmp.Equal(got.Issues, tt.want, opts) {
+				t.Fatalf("processCheckstyle() diff: %s", cmp.Diff(tt.want, got.Issues, opts))
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// regexGroups are the named groups supported in the pattern of the "regex"
+// processor.
+var regexGroups = map[string]bool{
+	"file":       true,
+	"line":       true,
+	"column":     true,
+	"end_line":   true,
+	"end_column": true,
+	"code":       true,
+	"title":      true,
+}
+
+// processRegex converts the lines of a tool's output to a Result using the
+// pattern, which must match a whole line. The named groups of the pattern are
+// the fields of the issue, and the file and line groups are required. The
+// lines which don't match are handled as per the unmatched policy, while the
+// empty lines are skipped.
+func processRegex(b []byte, pattern, unmatched string) (*Result, error) {
+	if err := validatePolicy("unmatched", unmatched); err != nil {
+		return nil, err
+	}
+
+	re, err := compileRegexPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Issues: []*Issue{}}
+
+	for i, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		match := re.FindStringSubmatch(line)
+		if match == nil {
+			if unmatched == policyWarn || unmatched == policyFail {
+				res.warnings = append(res.warnings, fmt.Sprintf(
+					"Line %d of the output file does not match the processor pattern: %q", i+1, line,
+				))
+				res.failed = res.failed || unmatched == policyFail
+			}
+			continue
+		}
+
+		issue, err := regexIssue(re, match)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		res.Issues = append(res.Issues, issue)
+	}
+
+	return res, nil
+}
+
+// compileRegexPattern compiles the pattern anchored to the whole line, and
+// checks its named groups.
+func compileRegexPattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, errors.New("the pattern is required with the regex format")
+	}
+
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for _, name := range re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+
+		if !regexGroups[name] {
+			return nil, fmt.Errorf("unsupported group %q in the pattern", name)
+		}
+		names[name] = true
+	}
+
+	for _, name := range []string{"file", "line"} {
+		if !names[name] {
+			return nil, fmt.Errorf("the pattern has no %q group", name)
+		}
+	}
+
+	return re, nil
+}
+
+// regexIssue returns the issue for the submatches of a line.
+func regexIssue(re *regexp.Regexp, match []string) (*Issue, error) {
+	issue := &Issue{}
+	var end Location
+
+	for i, name := range re.SubexpNames() {
+		value := match[i]
+		if name == "" || value == "" {
+			continue
+		}
+
+		switch name {
+		case "file":
+			issue.Position.File = value
+		case "code":
+			issue.Code = value
+		case "title":
+			issue.Title = value
+
+		default:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q", name, value)
+			}
+
+			switch name {
+			case "line":
+				issue.Position.Start.Line = n
+			case "column":
+				issue.Position.Start.Column = n
+			case "end_line":
+				end.Line = n
+			case "end_column":
+				end.Column = n
+			}
+		}
+	}
+
+	if end.Line != 0 || end.Column != 0 {
+		if end.Line == 0 {
+			end.Line = issue.Position.Start.Line
+		}
+		issue.Position.End = &end
+	}
+
+	return issue, nil
+}
@@ -0,0 +1,147 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestProcessRegex(t *testing.T) {
+	const flake8Pattern = `(?P<file>[^:]+):(?P<line>\d+):(?P<column>\d+): (?P<code>\S+) (?P<title>.*)`
+
+	tests := []struct {
+		name         string
+		output       string
+		pattern      string
+		unmatched    string
+		want         []*Issue
+		wantWarnings []string
+		wantFailed   bool
+		wantErr      bool
+	}{
+		{
+			name: "flake8",
+			output: `app.py:1:1: F401 'os' imported but unused
+pkg/util.py:10:80: E501 line too long (88 > 79 characters)
+
+`,
+			pattern: flake8Pattern,
+			want: []*Issue{
+				{
+					Code:     "F401",
+					Title:    "'os' imported but unused",
+					Position: IssuePosition{File: "app.py", Start: Location{Line: 1, Column: 1}},
+				},
+				{
+					Code:     "E501",
+					Title:    "line too long (88 > 79 characters)",
+					Position: IssuePosition{File: "pkg/util.py", Start: Location{Line: 10, Column: 80}},
+				},
+			},
+		},
+		{
+			name:    "end position",
+			output:  "main.c:3:5-3:9: warning: unused variable 'a' [-Wunused-variable]\r\n",
+			pattern: `(?P<file>[^:]+):(?P<line>\d+):(?P<column>\d+)-(?P<end_line>\d+):(?P<end_column>\d+): warning: (?P<title>.*) \[(?P<code>[^]]+)\]`,
+			want: []*Issue{
+				{
+					Code:  "-Wunused-variable",
+					Title: "unused variable 'a'",
+					Position: IssuePosition{
+						File:  "main.c",
+						Start: Location{Line: 3, Column: 5},
+						End:   &Location{Line: 3, Column: 9},
+					},
+				},
+			},
+		},
+		{
+			name:    "unmatched lines are ignored by default",
+			output:  "Running the linter\napp.py:1:1: F401 'os' imported but unused\n",
+			pattern: flake8Pattern,
+			want: []*Issue{
+				{
+					Code:     "F401",
+					Title:    "'os' imported but unused",
+					Position: IssuePosition{File: "app.py", Start: Location{Line: 1, Column: 1}},
+				},
+			},
+		},
+		{
+			name:      "unmatched lines are warned about",
+			output:    "app.py:1: F401 'os' imported but unused\n",
+			pattern:   flake8Pattern,
+			unmatched: "warn",
+			want:      []*Issue{},
+			wantWarnings: []string{
+				`Line 1 of the output file does not match the processor pattern: "app.py:1: F401 'os' imported but unused"`,
+			},
+		},
+		{
+			name:      "unmatched lines fail",
+			output:    "app.py:1: F401 'os' imported but unused\n",
+			pattern:   flake8Pattern,
+			unmatched: "fail",
+			want:      []*Issue{},
+			wantWarnings: []string{
+				`Line 1 of the output file does not match the processor pattern: "app.py:1: F401 'os' imported but unused"`,
+			},
+			wantFailed: true,
+		},
+		{
+			name:    "pattern matches the whole line",
+			output:  "app.py:1:1: F401 'os' imported but unused\n",
+			pattern: `(?P<file>[^:]+):(?P<line>\d+)`,
+			want:    []*Issue{},
+		},
+		{
+			name:    "missing line group",
+			pattern: `(?P<file>[^:]+): (?P<title>.*)`,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported group",
+			pattern: `(?P<file>[^:]+):(?P<line>\d+): (?P<message>.*)`,
+			wantErr: true,
+		},
+		{
+			name:      "unsupported unmatched policy",
+			pattern:   flake8Pattern,
+			unmatched: "error",
+			wantErr:   true,
+		},
+		{
+			name:    "invalid line",
+			output:  "app.py:one: F401 'os' imported but unused\n",
+			pattern: `(?P<file>[^:]+):(?P<line>[^:]+): (?P<code>\S+) (?P<title>.*)`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := processRegex([]byte(tt.output), tt.pattern, tt.unmatched)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("processRegex() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			opts := cmpopts.IgnoreUnexported(IssuePosition{})
+			if !cmp.Equal(got.Issues, tt.want, opts) {
+				t.Fatalf("processRegex() diff: %s", cmp.Diff(tt.want, got.Issues, opts))
+			}
+
+			if !cmp.Equal(got.warnings, tt.wantWarnings) {
+				t.Fatalf("unexpected warnings, diff: %s", cmp.Diff(tt.wantWarnings, got.warnings))
+			}
+
+			if got.failed != tt.wantFailed {
+				t.Fatalf("expected failed: %v, got: %v", tt.wantFailed, got.failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+)
+
+// processSARIF converts a SARIF 2.1.0 log to a Result. The issue code is the
+// ruleId of a result, and its title is the message text. The position is the
+// region of the first physical location of the result, and the results without
+// one are skipped. The file paths are resolved using the originalUriBaseIds of
+// each run. A relative path whose base is not defined in the log, like
+// %SRCROOT%, is relative to the code path.
+func processSARIF(b []byte) (*Result, error) {
+	var sarif sarifLog
+	if err := json.Unmarshal(b, &sarif); err != nil {
+		return nil, err
+	}
+
+	res := &Result{Issues: []*Issue{}}
+
+	for _, run := range sarif.Runs {
+		for _, result := range run.Results {
+			if len(result.Locations) == 0 {
+				continue
+			}
+
+			location := result.Locations[0].PhysicalLocation
+			if location.ArtifactLocation.URI == "" {
+				continue
+			}
+
+			file, err := sarifArtifactPath(run.OriginalURIBaseIDs, &location.ArtifactLocation, nil)
+			if err != nil {
+				return nil, err
+			}
+
+			issue := &Issue{
+				Code:     result.RuleID,
+				Title:    result.Message.Text,
+				Position: IssuePosition{File: file},
+			}
+
+			if region := location.Region; region != nil {
+				issue.Position.Start = Location{Line: region.StartLine, Column: region.StartColumn}
+
+				if region.EndLine != 0 || region.EndColumn != 0 {
+					// The end line defaults to the start line in SARIF.
+					endLine := region.EndLine
+					if endLine == 0 {
+						endLine = region.StartLine
+					}
+
+					// The end column is exclusive in SARIF, unlike the end
+					// columns of the pragmas.
+					endColumn := region.EndColumn
+					if endColumn > 0 {
+						endColumn--
+					}
+
+					issue.Position.End = &Location{Line: endLine, Column: endColumn}
+				}
+			}
+
+			res.Issues = append(res.Issues, issue)
+		}
+	}
+
+	return res, nil
+}
+
+// sarifArtifactPath returns the file path of the artifact location, resolving
+// its uriBaseId using the bases. The seen base IDs are used to detect cycles.
+func sarifArtifactPath(
+	bases map[string]*sarifArtifactLocation,
+	location *sarifArtifactLocation,
+	seen map[string]bool,
+) (string, error) {
+	u, err := url.Parse(location.URI)
+	if err != nil {
+		return "", err
+	}
+
+	if u.Scheme == "file" {
+		return pathFromFileURI(u), nil
+	}
+
+	if u.IsAbs() {
+		return "", fmt.Errorf("unsupported artifact URI %q", location.URI)
+	}
+
+	path := filepath.FromSlash(u.Path)
+
+	base, ok := bases[location.URIBaseID]
+	if location.URIBaseID == "" || !ok || base.URI == "" {
+		return path, nil
+	}
+
+	if seen[location.URIBaseID] {
+		return "", errors.New("cyclic originalUriBaseIds " + location.URIBaseID)
+	}
+
+	if seen == nil {
+		seen = make(map[string]bool)
+	}
+	seen[location.URIBaseID] = true
+
+	basePath, err := sarifArtifactPath(bases, base, seen)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(basePath, path), nil
+}
+
+// pathFromFileURI returns the file path of a file URI, the inverse of fileURI.
+func pathFromFileURI(u *url.URL) string {
+	path := u.Path
+
+	// Windows paths like /C:/foo don't have the leading slash.
+	if len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+
+	return filepath.Clean(filepath.FromSlash(path))
+}
@@ -0,0 +1,170 @@
+package runner
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestProcessSARIF(t *testing.T) {
+	tests := []struct {
+		name    string
+		sarif   string
+		want    []*Issue
+		wantErr bool
+	}{
+		{
+			name: "multiple runs",
+			sarif: `{
+  "version": "2.1.0",
+  "runs": [
+    {
+      "results": [
+        {
+          "ruleId": "GO-W1000",
+          "message": { "text": "Unused variable" },
+          "locations": [{
+            "physicalLocation": {
+              "artifactLocation": { "uri": "main.go" },
+              "region": { "startLine": 3, "startColumn": 5, "endLine": 3, "endColumn": 8 }
+            }
+          }]
+        }
+      ]
+    },
+    {
+      "results": [
+        {
+          "ruleId": "GO-W1001",
+          "message": { "text": "Useless assignment" },
+          "locations": [{
+            "physicalLocation": {
+              "artifactLocation": { "uri": "pkg/foo%20bar.go" },
+              "region": { "startLine": 7 }
+            }
+          }]
+        },
+        {
+          "ruleId": "GO-W1002",
+          "message": { "text": "No location" }
+        }
+      ]
+    }
+  ]
+}`,
+			want: []*Issue{
+				{
+					Code:  "GO-W1000",
+					Title: "Unused variable",
+					Position: IssuePosition{
+						File:  "main.go",
+						Start: Location{Line: 3, Column: 5},
+						End:   &Location{Line: 3, Column: 7},
+					},
+				},
+				{
+					Code:     "GO-W1001",
+					Title:    "Useless assignment",
+					Position: IssuePosition{File: filepath.Join("pkg", "foo bar.go"), Start: Location{Line: 7}},
+				},
+			},
+		},
+		{
+			name: "original URI base IDs",
+			sarif: `{
+  "version": "2.1.0",
+  "runs": [
+    {
+      "originalUriBaseIds": {
+        "ROOT": { "uri": "file:///code/" },
+        "PKG": { "uri": "pkg/", "uriBaseId": "ROOT" }
+      },
+      "results": [
+        {
+          "ruleId": "GO-W1000",
+          "message": { "text": "Unused variable" },
+          "locations": [{
+            "physicalLocation": {
+              "artifactLocation": { "uri": "foo.go", "uriBaseId": "PKG" },
+              "region": { "startLine": 1, "endColumn": 4 }
+            }
+          }]
+        },
+        {
+          "ruleId": "GO-W1000",
+          "message": { "text": "Unused variable" },
+          "locations": [{
+            "physicalLocation": {
+              "artifactLocation": { "uri": "main.go", "uriBaseId": "%SRCROOT%" },
+              "region": { "startLine": 2 }
+            }
+          }]
+        }
+      ]
+    }
+  ]
+}`,
+			want: []*Issue{
+				{
+					Code:  "GO-W1000",
+					Title: "Unused variable",
+					Position: IssuePosition{
+						File:  filepath.FromSlash("/code/pkg/foo.go"),
+						Start: Location{Line: 1},
+						End:   &Location{Line: 1, Column: 3},
+					},
+				},
+				{
+					Code:     "GO-W1000",
+					Title:    "Unused variable",
+					Position: IssuePosition{File: "main.go", Start: Location{Line: 2}},
+				},
+			},
+		},
+		{
+			name: "cyclic original URI base IDs",
+			sarif: `{
+  "runs": [
+    {
+      "originalUriBaseIds": {
+        "A": { "uri": "a/", "uriBaseId": "B" },
+        "B": { "uri": "b/", "uriBaseId": "A" }
+      },
+      "results": [
+        {
+          "ruleId": "GO-W1000",
+          "locations": [{ "physicalLocation": { "artifactLocation": { "uri": "main.go", "uriBaseId": "A" } } }]
+        }
+      ]
+    }
+  ]
+}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid JSON",
+			sarif:   `{"runs": [`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := processSARIF([]byte(tt.sarif))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("processSARIF() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			opts := cmpopts.IgnoreUnexported(IssuePosition{})
+			if !cmp.Equal(got.Issues, tt.want, opts) {
+				t.Fatalf("processSARIF() diff: %s", cmp.Diff(tt.want, got.Issues, opts))
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApplyErrorsPolicy(t *testing.T) {
+	errs := []string{"Unable to parse main.go", "Analyzer crashed"}
+	warnings := []string{
+		"The analyzer reported an error: Unable to parse main.go",
+		"The analyzer reported an error: Analyzer crashed",
+	}
+
+	tests := []struct {
+		name         string
+		policy       string
+		errors       []string
+		wantWarnings []string
+		wantFailed   bool
+		wantErr      bool
+	}{
+		{name: "default", errors: errs, wantWarnings: warnings},
+		{name: "ignore", policy: "ignore", errors: errs},
+		{name: "warn", policy: "warn", errors: errs, wantWarnings: warnings},
+		{name: "fail", policy: "fail", errors: errs, wantWarnings: warnings, wantFailed: true},
+		{name: "fail without errors", policy: "fail"},
+		{name: "unsupported policy", policy: "error", errors: errs, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &Result{Errors: tt.errors}
+
+			err := applyErrorsPolicy(res, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyErrorsPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if !cmp.Equal(res.warnings, tt.wantWarnings) {
+				t.Fatalf("unexpected warnings, diff: %s", cmp.Diff(tt.wantWarnings, res.warnings))
+			}
+
+			if res.failed != tt.wantFailed {
+				t.Fatalf("expected failed: %v, got: %v", tt.wantFailed, res.failed)
+			}
+		})
+	}
+}
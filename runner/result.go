@@ -1,13 +1,35 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
-	"log"
 	"path/filepath"
 )
 
 type Result struct {
 	Issues []*Issue `json:"issues"`
+
+	// Errors is the errors reported by the analyzer, like a crash while
+	// analyzing a file.
+	Errors []string `json:"errors,omitempty"`
+
+	// Metrics is the metrics reported by the analyzer.
+	Metrics []*Metric `json:"metrics,omitempty"`
+
+	// warnings are printed along with the results, like the lines a built-in
+	// processor could not process.
+	warnings []string
+
+	// failed fails the checks regardless of the issues.
+	failed bool
+}
+
+// Metric is the value of a metric reported by the analyzer, like the number of
+// lines of code, in an optional namespace, like a language.
+type Metric struct {
+	Code      string  `json:"code"`
+	Namespace string  `json:"namespace,omitempty"`
+	Value     float64 `json:"value"`
 }
 
 type Issue struct {
@@ -29,20 +51,31 @@ type Location struct {
 	Column int `json:"column"`
 }
 
-func unmarshalResult(b []byte, codePath string) (*Result, error) {
+func unmarshalResult(ctx context.Context, b []byte, codePath string) (*Result, error) {
 	var res Result
 	err := json.Unmarshal(b, &res)
 	if err != nil {
 		return nil, err
 	}
 
+	normalizeResult(ctx, &res, codePath)
+	return &res, nil
+}
+
+// normalizeResult normalizes the file paths of the issues, which are either
+// absolute or relative to the code path.
+func normalizeResult(ctx context.Context, res *Result, codePath string) {
 	for _, issue := range res.Issues {
-		issue.Position.fileNormalized, err = normalizeFilePath(filepath.Join(codePath, issue.Position.File))
+		file := issue.Position.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(codePath, file)
+		}
+
+		var err error
+		issue.Position.fileNormalized, err = normalizeFilePath(file)
 		if err != nil {
-			log.Println("Error normalizing file path for", issue, "err:", err)
+			loggerFrom(ctx).Println("Error normalizing file path for", issue, "err:", err)
 			continue
 		}
 	}
-
-	return &res, nil
 }
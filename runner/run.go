@@ -1,110 +1,129 @@
 package runner
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"time"
 )
 
-func Run(printer IssuePrinter, files []string, autofixDir string) (bool, error) {
-	config, err := ReadConfig(".scatr.toml")
+// Run runs the tests configured in the .scatr.toml in the cwd using the
+// options, and reports whether they passed. The relative paths in the options
+// are relative to the cwd. In case the context is cancelled, the running script
+// is killed and the files are restored, if Autofix was running in-place. See
+// Runner for running the tests without depending on the cwd.
+func Run(ctx context.Context, options Options) (bool, error) {
+	return runSuite(ctx, ".", options)
+}
+
+// runSuite runs the tests configured in the .scatr.toml in the directory, just
+// like Run.
+func runSuite(ctx context.Context, dir string, options Options) (bool, error) {
+	r, err := NewRunnerFromFile(filepath.Join(dir, ".scatr.toml"), options)
 	if err != nil {
 		return false, err
 	}
 
-	if !config.TestAutofix && !config.TestChecks {
-		return false, errors.New("nothing to do")
-	}
-
-	includedFiles, err := normalizeFileList(files, config.CodePath)
+	report, err := r.Run(ctx)
 	if err != nil {
 		return false, err
 	}
 
-	passed := true
+	return report.Passed, nil
+}
 
-	if config.TestChecks {
-		printer.PrintHeader("Testing checks")
-		res, testPassed, err := testChecks(config, includedFiles, printer)
-		if err != nil {
-			return false, err
-		}
+// testChecks runs the checks and diffs their result with the pragmas. The
+// result of the checks is returned along with the diff.
+func testChecks(
+	ctx context.Context,
+	config *Config,
+	includedFiles map[string]bool,
+	printer IssuePrinter,
+) (ChecksDiff, *Result, bool, error) {
+	result, err := runChecks(ctx, config)
+	if err != nil {
+		return nil, nil, false, err
+	}
 
-		if !testPassed {
-			printChecksDiff(res, printer)
-			passed = false
-		}
+	files, err := readFiles(ctx, config, includedFiles)
+	if err != nil {
+		return nil, nil, false, err
 	}
 
-	if config.TestAutofix {
-		printer.PrintHeader("Testing Autofix")
-		res, identical, testPassed, err := testAutofix(config, includedFiles, autofixDir)
-		if err != nil {
-			return false, err
-		}
+	for _, warning := range result.warnings {
+		printer.PrintWarning(warning)
+	}
+	printAnalyzerResult(result, printer)
+	printUnmatchedFiles(result, files, printer)
+	pragmasInside := printOutsidePragmas(config, files, printer)
 
-		if !testPassed {
-			printAutofixDiff(res, printer)
-			printIdenticalFiles(identical, printer)
-			passed = false
+	testedFiles := make([]string, 0, len(files))
+	for path := range files {
+		if !isExcluded(path, config.ExcludedDirs) {
+			testedFiles = append(testedFiles, path)
 		}
 	}
+	printTestedFiles(testedFiles, printer)
 
-	printer.PrintStatus(passed)
-	return passed, nil
+	res, passed := diffChecksResult(files, config.ExcludedDirs, includedFiles, result)
+	return res, result, passed && pragmasInside && !result.failed, err
 }
 
-func testChecks(
-	config *Config,
-	includedFiles map[string]bool,
-	printer IssuePrinter,
-) (checksDiff, bool, error) {
-	log.Printf("Running the checks test script with the interpreter %q\n", config.Checks.Interpreter)
-	log.Println("--- Checks run log ---")
+// runChecks runs the checks script and returns the result of processing its
+// output.
+func runChecks(ctx context.Context, config *Config) (*Result, error) {
+	logger := loggerFrom(ctx)
+	logger.Printf("Running the checks test script with the interpreter %q\n", config.Checks.Interpreter)
+	logger.Println("--- Checks run log ---")
 
 	startTime := time.Now()
-	err := runScript(config.Checks, config.CodePath, map[string]string{})
+	err := runScript(ctx, config, config.Checks, stageChecks, map[string]string{})
 	if err != nil {
-		return nil, false, err
+		return nil, err
 	}
 
-	log.Println("Checks test script completed in", time.Since(startTime))
+	logger.Println("Checks test script completed in", time.Since(startTime))
 
-	result, err := runProcessor(config.Processor, config.Checks.OutputFile, config.CodePath)
+	result, err := runProcessor(ctx, config, config.resolvePath(config.Checks.OutputFile))
 	if err != nil {
-		return nil, false, err
+		return nil, err
 	}
 
-	files, err := readFiles(config, includedFiles)
-	if err != nil {
-		return nil, false, err
+	for _, metric := range result.Metrics {
+		if metric.Namespace == "" {
+			logger.Printf("Metric %s: %v\n", metric.Code, metric.Value)
+		} else {
+			logger.Printf("Metric %s (%s): %v\n", metric.Code, metric.Namespace, metric.Value)
+		}
 	}
 
-	printUnmatchedFiles(result, files, printer)
+	if err := applyErrorsPolicy(result, config.Processor.Errors); err != nil {
+		return nil, err
+	}
 
-	res, passed := diffChecksResult(files, config.ExcludedDirs, includedFiles, result)
-	return res, passed, err
+	return result, nil
 }
 
 func testAutofix(
+	ctx context.Context,
 	config *Config,
 	includedFiles map[string]bool,
 	autofixDir string,
-) (autofixDiff, identicalGoldenFiles, bool, error) {
-	log.Println("Backing up the potentially Autofix'able files")
-	backup, err := NewAutofixBackup(config, includedFiles, autofixDir)
+	printer IssuePrinter,
+) (AutofixDiff, identicalGoldenFiles, bool, error) {
+	logger := loggerFrom(ctx)
+	logger.Println("Backing up the potentially Autofix'able files")
+	backup, err := NewAutofixBackup(ctx, config, includedFiles, autofixDir)
 	if err != nil {
 		return nil, nil, false, err
 	}
 
-	diff, identical, passed, err := runAutofixTests(config, autofixDir, backup)
+	diff, identical, passed, err := runAutofixTests(ctx, config, autofixDir, backup, printer)
 	if err != nil {
-		log.Println("Autofix run error:", err)
-		restoreErr := restoreBackup(backup)
+		logger.Println("Autofix run error:", err)
+		restoreErr := restoreBackup(ctx, backup)
 		if restoreErr != nil {
 			return nil, nil, false,
 				fmt.Errorf("autofix err: %s, restore err: %s", err.Error(), restoreErr.Error())
@@ -113,14 +132,15 @@ func testAutofix(
 		return nil, nil, false, err
 	}
 
-	return diff, identical, passed, restoreBackup(backup)
+	return diff, identical, passed, restoreBackup(ctx, backup)
 }
 
-func restoreBackup(backup *AutofixBackup) error {
-	log.Println("Restoring the Autofix backup")
+func restoreBackup(ctx context.Context, backup *AutofixBackup) error {
+	logger := loggerFrom(ctx)
+	logger.Println("Restoring the Autofix backup")
 	err := backup.RestoreAndDestroy()
 	if err != nil {
-		log.Println("Unable to restore Autofix backup, err:", err)
+		logger.Println("Unable to restore Autofix backup, err:", err)
 		return err
 	}
 
@@ -128,75 +148,83 @@ func restoreBackup(backup *AutofixBackup) error {
 }
 
 func runAutofixTests(
+	ctx context.Context,
 	config *Config,
 	autofixDir string,
 	backup *AutofixBackup,
-) (autofixDiff, identicalGoldenFiles, bool, error) {
-	log.Println("Checking for identical original and golden files")
-	identical, passed, err := checkIdenticalGoldenFile(config.CodePath, config.ExcludedDirs, backup)
+	printer IssuePrinter,
+) (AutofixDiff, identicalGoldenFiles, bool, error) {
+	testedFiles, err := goldenTestedFiles(backup.CodePath, config.ExcludedDirs, backup)
 	if err != nil {
 		return nil, nil, false, err
 	}
+	printTestedFiles(testedFiles, printer)
 
-	log.Printf("Running the Autofix test script with the interpreter %q\n", config.Checks.Interpreter)
-	log.Println("--- Autofix run log ---")
-
-	startTime := time.Now()
-
-	var outputDir string
-	if autofixDir == "" {
-		outputDir, err = os.Getwd()
-		if err != nil {
-			return nil, nil, false, err
-		}
-	} else {
-		outputDir, err = normalizeFilePath(autofixDir)
-		if err != nil {
-			return nil, nil, false, err
-		}
+	loggerFrom(ctx).Println("Checking for identical original and golden files")
+	identical, passed, err := checkIdenticalGoldenFile(backup.CodePath, config.ExcludedDirs, backup)
+	if err != nil {
+		return nil, nil, false, err
 	}
 
-	err = runScript(
-		config.Autofix,
-		config.CodePath,
-		map[string]string{"OUTPUT_DIR": outputDir},
-	)
+	err = runAutofixScript(ctx, config, autofixDir)
 	if err != nil {
 		return nil, nil, false, err
 	}
 
-	err = os.Unsetenv("OUTPUT_DIR")
+	diff, diffPassed, err := diffAutofixResult(backup.CodePath, config.ExcludedDirs, backup)
 	if err != nil {
 		return nil, nil, false, err
 	}
 
-	log.Println("Autofix test script completed in", time.Since(startTime))
+	return diff, identical, passed && diffPassed, nil
+}
+
+// runAutofixScript runs the Autofix script with OUTPUT_DIR set to the Autofix
+// directory, or to the directory of the config if the Autofix is performed
+// in-place.
+func runAutofixScript(ctx context.Context, config *Config, autofixDir string) error {
+	logger := loggerFrom(ctx)
+	logger.Printf("Running the Autofix test script with the interpreter %q\n", config.Autofix.Interpreter)
+	logger.Println("--- Autofix run log ---")
+
+	startTime := time.Now()
+
+	outputDir, err := normalizeFilePath(config.resolvePath(autofixDir))
+	if err != nil {
+		return err
+	}
 
-	diff, diffPassed, err := diffAutofixResult(config.CodePath, config.ExcludedDirs, backup)
+	err = runScript(ctx, config, config.Autofix, stageAutofix, map[string]string{"OUTPUT_DIR": outputDir})
 	if err != nil {
-		return nil, nil, false, err
+		return err
 	}
 
-	return diff, identical, passed && diffPassed, nil
+	logger.Println("Autofix test script completed in", time.Since(startTime))
+	return nil
 }
 
-// runScript runs a test runner script with the provided interpreter and pipes
-// the command's stdout and stderr on the host's stderr
-func runScript(cfg TestRunnerConfig, codePath string, env map[string]string) error {
+// runScript runs a test runner script with the provided interpreter in the
+// directory of the config, and pipes the command's stdout and stderr to the
+// script output of the run, the host's stderr by default. The script is killed
+// in case it does not complete within the timeout of the stage. The provided
+// env is passed to the script along with CODE_PATH, see scriptEnv.
+func runScript(
+	ctx context.Context,
+	config *Config,
+	cfg TestRunnerConfig,
+	stage string,
+	env map[string]string,
+) error {
 	if env == nil {
 		env = make(map[string]string)
 	}
 
-	codePathAbs, err := normalizeFilePath(codePath)
+	codePathAbs, err := config.codePathAbs()
 	if err != nil {
 		return err
 	}
 
 	env["CODE_PATH"] = codePathAbs
-	err = setEnv(env)
-	if err != nil {
-		return err
-	}
 
 	scriptFile, err := os.CreateTemp("", "scatr-script")
 	if err != nil {
@@ -207,7 +235,7 @@ func runScript(cfg TestRunnerConfig, codePath string, env map[string]string) err
 	defer func(name string) {
 		err := os.Remove(name)
 		if err != nil {
-			log.Println("Cleanup error", err)
+			loggerFrom(ctx).Println("Cleanup error", err)
 		}
 	}(scriptFilePath)
 
@@ -227,31 +255,33 @@ func runScript(cfg TestRunnerConfig, codePath string, env map[string]string) err
 	}
 
 	cmd := exec.Command(cfg.Interpreter, append(cfg.Args, scriptFilePath)...)
+	cmd.Env = scriptEnv(config.CleanEnv, cfg.Env, env)
+	cmd.Dir = config.Dir
 
 	if cfg.Interactive {
 		cmd.Stdin = os.Stdin
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 	} else {
-		cmd.Stdout = os.Stderr
-		cmd.Stderr = os.Stderr
+		output := scriptOutputFrom(ctx)
+		cmd.Stdout = output
+		cmd.Stderr = output
 		cmd.Stdin = nil
-	}
 
-	err = cmd.Run()
-	if err != nil {
-		return err
+		// An interactive script needs to stay in the foreground process group to
+		// read from the terminal.
+		setProcessGroup(cmd)
 	}
 
-	return unsetEnv(env)
+	return runCommand(ctx, cmd, stage, cfg.Timeout)
 }
 
-func normalizeFileList(files []string, codePath string) (map[string]bool, error) {
+func normalizeFileList(ctx context.Context, files []string, codePath string) (map[string]bool, error) {
 	m := make(map[string]bool)
 	for _, f := range files {
 		normalized, err := normalizeFilePath(filepath.Join(codePath, f))
 		if err != nil {
-			log.Println("Error normalizing the file path", f, "err:", err)
+			loggerFrom(ctx).Println("Error normalizing the file path", f, "err:", err)
 			continue
 		}
 
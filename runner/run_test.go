@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"log"
@@ -21,7 +22,7 @@ func init() {
 func TestTestChecks(t *testing.T) {
 	type testResult struct {
 		Passed bool       `json:"passed"`
-		Result checksDiff `json:"result"`
+		Result ChecksDiff `json:"result"`
 	}
 
 	tests := []string{
@@ -30,7 +31,19 @@ func TestTestChecks(t *testing.T) {
 		"go_multiple_pragmas", "go_failing_multiple_files", "go_included_files",
 		"go_code_path", "go_code_path_included_files", "go_excluded_dirs",
 		"go_issue_codes",
+		"go_sarif",
+		"go_analyzer_errors",
+		"go_end_positions",
+		"go_title_patterns",
+		"go_forbidden",
+		"go_occurrences",
+		"go_line_offsets",
+		"go_line_offsets_outside",
 		"py", "py_failing",
+		"js_checkstyle",
+		"py_regex",
+		"css_block_comments",
+		"py_string_literals",
 	}
 
 	cwd, err := os.Getwd()
@@ -74,12 +87,12 @@ func TestTestChecks(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			normalized, err := normalizeFileList(files, config.CodePath)
+			normalized, err := normalizeFileList(context.Background(), files, config.CodePath)
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			got, passed, err := testChecks(config, normalized, &NOPIssuePrinter{})
+			got, _, passed, err := testChecks(context.Background(), config, normalized, &NOPIssuePrinter{})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -95,7 +108,10 @@ func TestTestChecks(t *testing.T) {
 				}
 
 				if len(issues.Unexpected) == 0 &&
-					len(issues.NotRaised) == 0 {
+					len(issues.NotRaised) == 0 &&
+					len(issues.EndMismatch) == 0 &&
+					len(issues.Forbidden) == 0 &&
+					len(issues.CountMismatch) == 0 {
 					continue
 				}
 
@@ -103,9 +119,12 @@ func TestTestChecks(t *testing.T) {
 					cmpopts.IgnoreFields(IssuePosition{}, "fileNormalized"),
 					cmpopts.IgnoreFields(IssuePosition{}, "File"),
 					cmpopts.SortSlices(func(a, b any) bool {
-						if a, ok := a.(*Issue); ok {
-							b := b.(*Issue)
-							return a.Code < b.Code
+						if a, ok := a.(*FailedIssue); ok {
+							b := b.(*FailedIssue)
+							if a.Code != b.Code {
+								return a.Code < b.Code
+							}
+							return a.Position.Start.Line < b.Position.Start.Line
 						}
 
 						return false
@@ -185,12 +204,12 @@ func TestTestAutofix(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			normalized, err := normalizeFileList(files, config.CodePath)
+			normalized, err := normalizeFileList(context.Background(), files, config.CodePath)
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			got, identical, passed, err := testAutofix(config, normalized, "")
+			got, identical, passed, err := testAutofix(context.Background(), config, normalized, "", &NOPIssuePrinter{})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -300,12 +319,12 @@ func TestTestAutofix_AutofixDir(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			normalized, err := normalizeFileList(files, config.CodePath)
+			normalized, err := normalizeFileList(context.Background(), files, config.CodePath)
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			got, identical, passed, err := testAutofix(config, normalized, autofixDir)
+			got, identical, passed, err := testAutofix(context.Background(), config, normalized, autofixDir, &NOPIssuePrinter{})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -0,0 +1,238 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+)
+
+// Options configures a Runner. The zero value runs the tests of all the files,
+// with Autofix in-place, without printing the results.
+type Options struct {
+	// CodePath overrides the code_path of the config. It is absolute, or
+	// relative to the directory of the config.
+	CodePath string
+
+	// Files is the subset of the files to run the tests on, relative to the
+	// code path. All the files matching the files glob are tested if it is
+	// empty.
+	Files []string
+
+	// AutofixDir is the directory where the Autofix takes place. It is
+	// absolute, or relative to the directory of the config. The Autofix is
+	// performed in-place if it is empty.
+	AutofixDir string
+
+	// Lint lints the pragmas before running the tests, and fails the run in
+	// case any malformed pragma is found.
+	Lint bool
+
+	// Timeout is used for the stages which don't have a timeout configured.
+	Timeout time.Duration
+
+	// Logger is used for the logs of the run. The standard logger is used if it
+	// is nil.
+	Logger *log.Logger
+
+	// ScriptOutput is where the output of the scripts is written. The host's
+	// stderr is used if it is nil.
+	ScriptOutput io.Writer
+
+	// Printers print the results as the run progresses.
+	Printers []IssuePrinter
+}
+
+// Runner runs the tests configured in a Config without depending on the cwd,
+// which allows running multiple tests in the same process.
+type Runner struct {
+	config  *Config
+	options Options
+}
+
+// NewRunner returns a Runner for the config. The config is used as is, so a
+// config which is not read using ReadConfig should set the defaults itself,
+// like the interpreters. The relative paths in the config are relative to its
+// Dir, or to the cwd if it is empty.
+func NewRunner(config *Config, options Options) *Runner {
+	// The config is copied, as the options are applied to it.
+	c := *config
+	if options.CodePath != "" {
+		c.CodePath = options.CodePath
+	}
+	c.setDefaultTimeout(options.Timeout)
+
+	return &Runner{config: &c, options: options}
+}
+
+// NewRunnerFromFile returns a Runner for the config at the provided path, like
+// a .scatr.toml.
+func NewRunnerFromFile(configPath string, options Options) (*Runner, error) {
+	config, err := ReadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRunner(config, options), nil
+}
+
+// Report is the result of a run.
+type Report struct {
+	Passed bool
+
+	// LintFindings is the malformed pragmas, in case the pragmas were linted.
+	LintFindings []*LintFinding
+
+	// Checks is the result of testing the checks, or nil if they were not
+	// tested.
+	Checks *ChecksReport
+
+	// Autofix is the result of testing the Autofix, or nil if it was not
+	// tested.
+	Autofix *AutofixReport
+
+	Duration time.Duration
+}
+
+// ChecksReport is the result of testing the checks.
+type ChecksReport struct {
+	Passed bool
+	Diff   ChecksDiff
+
+	// Errors is the errors reported by the analyzer, which are handled as per
+	// the errors policy of the processor.
+	Errors []string
+
+	// Metrics is the metrics reported by the analyzer.
+	Metrics []*Metric
+
+	Duration time.Duration
+}
+
+// AutofixReport is the result of testing the Autofix.
+type AutofixReport struct {
+	Passed bool
+	Diff   AutofixDiff
+
+	// Identical is the paths of the files which are identical to their golden
+	// file, and so are not Autofix'ed, in the same form as the keys of Diff.
+	Identical []string
+
+	Duration time.Duration
+}
+
+// Run runs the tests and returns the report. The results are printed using
+// the printers of the Runner as the run progresses. In case the context is
+// cancelled, the running script is killed and the files are restored, if
+// Autofix was running in-place, and the context's error is returned.
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	config := r.config
+	startTime := time.Now()
+
+	if !config.TestAutofix && !config.TestChecks {
+		return nil, errors.New("nothing to do")
+	}
+
+	ctx = withRunLog(ctx, r.options.logger(), r.options.scriptOutput())
+	printer := r.options.printer()
+
+	includedFiles, err := normalizeFileList(ctx, r.options.Files, config.resolvePath(config.CodePath))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Passed: true}
+
+	if r.options.Lint {
+		printer.PrintHeader("Linting pragmas")
+		report.LintFindings, err = lintPragmas(ctx, config, includedFiles, printer)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Passed = len(report.LintFindings) == 0
+	}
+
+	if config.TestChecks {
+		printer.PrintHeader("Testing checks")
+		checksStartTime := time.Now()
+		res, result, testPassed, err := testChecks(ctx, config, includedFiles, printer)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Checks = &ChecksReport{
+			Passed:   testPassed,
+			Diff:     res,
+			Errors:   result.Errors,
+			Metrics:  result.Metrics,
+			Duration: time.Since(checksStartTime),
+		}
+
+		if !testPassed {
+			printChecksDiff(res, printer)
+			report.Passed = false
+		}
+	}
+
+	if config.TestAutofix {
+		printer.PrintHeader("Testing Autofix")
+		autofixStartTime := time.Now()
+		res, identical, testPassed, err := testAutofix(ctx, config, includedFiles, r.options.AutofixDir, printer)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Autofix = &AutofixReport{
+			Passed:    testPassed,
+			Diff:      res,
+			Identical: make([]string, 0, len(identical)),
+			Duration:  time.Since(autofixStartTime),
+		}
+		for file := range identical {
+			report.Autofix.Identical = append(report.Autofix.Identical, file)
+		}
+		sort.Strings(report.Autofix.Identical)
+
+		if !testPassed {
+			printAutofixDiff(res, printer)
+			printIdenticalFiles(identical, printer)
+			report.Passed = false
+		}
+	}
+
+	printer.PrintStatus(report.Passed)
+
+	report.Duration = time.Since(startTime)
+	return report, nil
+}
+
+func (o *Options) logger() *log.Logger {
+	if o.Logger == nil {
+		return log.Default()
+	}
+
+	return o.Logger
+}
+
+func (o *Options) scriptOutput() io.Writer {
+	if o.ScriptOutput == nil {
+		return os.Stderr
+	}
+
+	return o.ScriptOutput
+}
+
+func (o *Options) printer() IssuePrinter {
+	switch len(o.Printers) {
+	case 0:
+		return &NOPIssuePrinter{}
+	case 1:
+		return o.Printers[0]
+	default:
+		return MultiIssuePrinter(o.Printers)
+	}
+}
@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRunner runs the test data of the checks and the Autofix without changing
+// the cwd, and checks the reports.
+func TestRunner(t *testing.T) {
+	tests := []struct {
+		name        string
+		dir         string
+		wantChecks  bool
+		wantAutofix bool
+		wantLog     string
+	}{
+		{
+			name:       "checks",
+			dir:        filepath.Join("testdata", "checks", "go_failing"),
+			wantChecks: true,
+			wantLog:    "Running the checks test script",
+		},
+		{
+			name:        "autofix",
+			dir:         filepath.Join("testdata", "autofix", "go_failing"),
+			wantAutofix: true,
+			wantLog:     "Restoring the Autofix backup",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := &bytes.Buffer{}
+
+			r, err := NewRunnerFromFile(filepath.Join(tt.dir, ".scatr.toml"), Options{
+				Logger:       log.New(logs, "", 0),
+				ScriptOutput: logs,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := r.Run(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if report.Passed {
+				t.Fatal("expected the run to fail")
+			}
+
+			if (report.Checks != nil) != tt.wantChecks || (report.Autofix != nil) != tt.wantAutofix {
+				t.Fatalf("unexpected stages in the report, checks: %v, autofix: %v",
+					report.Checks != nil, report.Autofix != nil)
+			}
+
+			mainFile, err := normalizeFilePath(filepath.Join(tt.dir, "main.go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if report.Checks != nil {
+				issues, ok := report.Checks.Diff[mainFile]
+				if !ok || len(issues.Unexpected) == 0 || report.Checks.Passed {
+					t.Fatalf("expected the checks of %s to fail, got: %v", mainFile, report.Checks.Diff)
+				}
+			}
+
+			if report.Autofix != nil {
+				autofixFile := filepath.Join(tt.dir, "main.go")
+				if _, ok := report.Autofix.Diff[autofixFile]; !ok || report.Autofix.Passed {
+					t.Fatalf("expected the Autofix of %s to fail, got: %v", autofixFile, report.Autofix.Diff)
+				}
+			}
+
+			if !strings.Contains(logs.String(), tt.wantLog) {
+				t.Fatalf("expected %q in the logs, got: %q", tt.wantLog, logs.String())
+			}
+		})
+	}
+}
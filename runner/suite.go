@@ -0,0 +1,435 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SuiteResult is the result of running a suite using RunSuites.
+type SuiteResult struct {
+	Suite    string // directory of the suite, relative to the cwd
+	Passed   bool
+	Err      error // error which stopped the suite from running, if any
+	Duration time.Duration
+}
+
+// SuitePrinter is implemented by the printers which group the results by suite
+// when running multiple suites.
+type SuitePrinter interface {
+	PrintSuiteHeader(suite string)
+	PrintSummary(results []*SuiteResult)
+}
+
+// DiscoverSuites returns the directories of the suites matched by the patterns.
+// A pattern ending with "/..." matches every directory containing a .scatr.toml
+// in the tree rooted at the pattern's directory, while any other pattern is the
+// directory of a suite. Hidden directories are skipped while walking the tree.
+func DiscoverSuites(patterns []string) ([]string, error) {
+	var suites []string
+	seen := make(map[string]bool)
+
+	add := func(dir string) {
+		dir = filepath.Clean(dir)
+		if !seen[dir] {
+			seen[dir] = true
+			suites = append(suites, dir)
+		}
+	}
+
+	for _, pattern := range patterns {
+		if !strings.HasSuffix(filepath.ToSlash(pattern), "...") {
+			_, err := os.Stat(filepath.Join(pattern, ".scatr.toml"))
+			if err != nil {
+				return nil, fmt.Errorf("%s is not a suite: %w", pattern, err)
+			}
+
+			add(pattern)
+			continue
+		}
+
+		root := filepath.Clean(strings.TrimSuffix(pattern, "..."))
+
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !d.IsDir() {
+				return nil
+			}
+
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+
+			_, err = os.Stat(filepath.Join(path, ".scatr.toml"))
+			if err == nil {
+				add(path)
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return suites, nil
+}
+
+// RunSuites runs each of the suites in the provided directories using the
+// options, just like Run, and prints a summary of the results. The results of
+// each suite are grouped under its header for the printers which implement
+// SuitePrinter. A suite which fails to run does not stop the other suites,
+// unless the context is cancelled. The AutofixDir of the options is relative to
+// the cwd, and in case it is set with multiple suites, each suite uses its own
+// directory in it.
+//
+// Up to jobs suites are run concurrently. The logs and the results of such a
+// suite are buffered, and printed in the order of the directories once the
+// suite completes. A suite testing Autofix in-place is not run along with the
+// other suites whose code paths overlap with its code path.
+func RunSuites(ctx context.Context, dirs []string, jobs int, options Options) (bool, error) {
+	if len(dirs) > 1 && len(options.Files) != 0 {
+		return false, errors.New("the files can't be set while running multiple suites")
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return false, err
+	}
+
+	autofixDir := options.AutofixDir
+	if autofixDir != "" {
+		autofixDir, err = filepath.Abs(autofixDir)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	suites := make([]*suiteRun, 0, len(dirs))
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return false, err
+		}
+
+		suite := &suiteRun{dir: abs, name: relativePath(cwd, abs), options: options}
+		suite.options.AutofixDir = autofixDir
+
+		if autofixDir != "" && len(dirs) > 1 {
+			suite.options.AutofixDir = filepath.Join(autofixDir, suite.name)
+			if err := os.MkdirAll(suite.options.AutofixDir, os.ModePerm); err != nil {
+				return false, err
+			}
+		}
+
+		suites = append(suites, suite)
+	}
+
+	ctx = withRunLog(ctx, options.logger(), options.scriptOutput())
+	printer := options.printer()
+
+	var results []*SuiteResult
+	if jobs > 1 && len(suites) > 1 {
+		results, err = runSuitesInParallel(ctx, printer, suites, jobs)
+	} else {
+		results, err = runSuitesInOrder(ctx, printer, suites)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	passed := true
+	for _, result := range results {
+		passed = passed && result.Passed
+	}
+
+	if suitePrinter, ok := printer.(SuitePrinter); ok {
+		suitePrinter.PrintSummary(results)
+	}
+
+	return passed, nil
+}
+
+// suiteRun is a suite to be run by RunSuites.
+type suiteRun struct {
+	dir     string // absolute path of the suite
+	name    string // path of the suite relative to the cwd
+	options Options
+}
+
+// run runs the suite and returns its result, using the logger and the script
+// output of the context. An error is only returned in case the context is
+// cancelled, as any other error is part of the result.
+func (s *suiteRun) run(ctx context.Context, printer IssuePrinter) (*SuiteResult, error) {
+	startTime := time.Now()
+	result := &SuiteResult{Suite: s.name}
+
+	options := s.options
+	options.Logger, options.ScriptOutput = loggerFrom(ctx), scriptOutputFrom(ctx)
+	options.Printers = []IssuePrinter{printer}
+
+	passed, err := runSuite(ctx, s.dir, options)
+	result.Duration = time.Since(startTime)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		result.Err = err
+		printer.PrintWarning(fmt.Sprintf("Unable to run the suite %s: %s", s.name, err))
+		return result, nil
+	}
+
+	result.Passed = passed
+	return result, nil
+}
+
+// codePath returns the code path of the suite, along with whether the suite
+// changes the files in it by testing Autofix in-place. It returns an empty path
+// in case the config can't be read.
+func (s *suiteRun) codePath() (string, bool) {
+	config, err := ReadConfig(filepath.Join(s.dir, ".scatr.toml"))
+	if err != nil {
+		// The error is reported once the suite is run.
+		return "", false
+	}
+
+	if s.options.CodePath != "" {
+		config.CodePath = s.options.CodePath
+	}
+
+	codePath, err := config.codePathAbs()
+	if err != nil {
+		return "", false
+	}
+
+	return codePath, config.TestAutofix && s.options.AutofixDir == ""
+}
+
+// runSuitesInOrder runs the suites one after the other, printing the results
+// as they come.
+func runSuitesInOrder(ctx context.Context, printer IssuePrinter, suites []*suiteRun) ([]*SuiteResult, error) {
+	results := make([]*SuiteResult, 0, len(suites))
+
+	for _, suite := range suites {
+		if suitePrinter, ok := printer.(SuitePrinter); ok {
+			suitePrinter.PrintSuiteHeader(suite.name)
+		}
+
+		result, err := suite.run(ctx, printer)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// bufferedSuite is the buffered output of a suite run in parallel.
+type bufferedSuite struct {
+	printer *bufferedIssuePrinter
+	logs    bytes.Buffer
+	result  *SuiteResult
+	err     error
+	done    chan struct{}
+}
+
+// runSuitesInParallel runs the suites using a pool of jobs workers. The output
+// of each suite is printed once it completes, after the output of the suites
+// before it.
+func runSuitesInParallel(
+	ctx context.Context,
+	printer IssuePrinter,
+	suites []*suiteRun,
+	jobs int,
+) ([]*SuiteResult, error) {
+	outputs := make([]*bufferedSuite, len(suites))
+	queue := make(chan int, len(suites))
+	for i := range suites {
+		outputs[i] = &bufferedSuite{printer: &bufferedIssuePrinter{}, done: make(chan struct{})}
+		queue <- i
+	}
+	close(queue)
+
+	locks := newPathLock()
+
+	var wg sync.WaitGroup
+	for j := 0; j < jobs; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range queue {
+				suite, output := suites[i], outputs[i]
+
+				// The remaining suites are skipped once the context is cancelled.
+				if output.err = ctx.Err(); output.err == nil {
+					logs := &lockedWriter{w: &output.logs}
+					suiteCtx := withRunLog(ctx, suiteLogger(loggerFrom(ctx), logs), logs)
+
+					codePath, write := suite.codePath()
+					if codePath != "" {
+						locks.lock(codePath, write)
+					}
+
+					output.result, output.err = suite.run(suiteCtx, output.printer)
+
+					if codePath != "" {
+						locks.unlock(codePath, write)
+					}
+				}
+
+				close(output.done)
+			}
+		}()
+	}
+
+	var err error
+	results := make([]*SuiteResult, 0, len(suites))
+
+	for i, output := range outputs {
+		<-output.done
+
+		if err != nil {
+			continue
+		}
+
+		if output.err != nil {
+			err = output.err
+			continue
+		}
+
+		if suitePrinter, ok := printer.(SuitePrinter); ok {
+			suitePrinter.PrintSuiteHeader(suites[i].name)
+		}
+
+		// The logs of the suite are written before its results, as they are
+		// mostly about the scripts run before the results are printed.
+		_, _ = scriptOutputFrom(ctx).Write(output.logs.Bytes())
+		output.printer.replay(printer)
+
+		results = append(results, output.result)
+	}
+
+	// All the workers are done by now, which also means that the files changed
+	// by an in-place Autofix have been restored.
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// suiteLogger returns a logger for a suite run in parallel, which writes to w
+// with the prefix and flags of the logger of the run. The logs are discarded in
+// case the logger of the run discards them.
+func suiteLogger(logger *log.Logger, w io.Writer) *log.Logger {
+	if logger.Writer() == io.Discard {
+		w = io.Discard
+	}
+
+	return log.New(w, logger.Prefix(), logger.Flags())
+}
+
+// pathLock is a readers-writer lock of the code paths of the suites. The
+// suites testing Autofix in-place lock their code paths for writing, as they
+// change the files, and the other suites lock them for reading. A path locked
+// for writing can't overlap with any other locked path.
+type pathLock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	readers map[string]int  // number of readers of each path
+	writers map[string]bool // paths locked for writing
+}
+
+func newPathLock() *pathLock {
+	l := &pathLock{readers: make(map[string]int), writers: make(map[string]bool)}
+	l.cond = sync.NewCond(&l.mu)
+
+	return l
+}
+
+// lock locks the path for writing if write is set, and for reading otherwise,
+// waiting for the conflicting paths which overlap with it to be unlocked.
+func (l *pathLock) lock(path string, write bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for l.conflicts(path, write) {
+		l.cond.Wait()
+	}
+
+	if write {
+		l.writers[path] = true
+	} else {
+		l.readers[path]++
+	}
+}
+
+func (l *pathLock) unlock(path string, write bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if write {
+		delete(l.writers, path)
+	} else if l.readers[path]--; l.readers[path] == 0 {
+		delete(l.readers, path)
+	}
+
+	l.cond.Broadcast()
+}
+
+// conflicts reports whether the path overlaps with a path locked for writing,
+// or with any locked path in case of a writer.
+func (l *pathLock) conflicts(path string, write bool) bool {
+	for locked := range l.writers {
+		if overlaps(locked, path) {
+			return true
+		}
+	}
+
+	if !write {
+		return false
+	}
+
+	for locked := range l.readers {
+		if overlaps(locked, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// overlaps reports whether one of the paths is inside the other.
+func overlaps(a, b string) bool {
+	return isSubPath(a, b) || isSubPath(b, a)
+}
+
+// isSubPath reports whether the path is the parent directory, or is inside it.
+func isSubPath(parent, path string) bool {
+	rel, err := filepath.Rel(parent, path)
+	if err != nil {
+		return false
+	}
+
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,249 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiscoverSuites(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "recursive",
+			patterns: []string{"testdata/suites/..."},
+			want:     []string{"testdata/suites/a", "testdata/suites/b/nested"},
+		},
+		{
+			name:     "recursive and duplicate suites",
+			patterns: []string{"testdata/suites/b/...", "testdata/suites/a", "testdata/suites/b/nested"},
+			want:     []string{"testdata/suites/b/nested", "testdata/suites/a"},
+		},
+		{
+			name:     "directory without a config",
+			patterns: []string{"testdata/suites/c"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiscoverSuites(tt.patterns)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DiscoverSuites() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			want := make([]string, 0, len(tt.want))
+			for _, suite := range tt.want {
+				want = append(want, filepath.FromSlash(suite))
+			}
+
+			if !tt.wantErr && !cmp.Equal(got, want) {
+				t.Fatalf("DiscoverSuites() diff: %s", cmp.Diff(want, got))
+			}
+		})
+	}
+}
+
+// TestRunSuites is an integration test for running multiple suites, using the
+// suites summary of the JSON report. The suites are run both one after the
+// other and in parallel, which must not change the report, nor where the
+// output of the scripts is written.
+func TestRunSuites(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(filepath.Join("testdata", "suites")); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := os.Chdir(cwd); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	for _, jobs := range []int{1, 3} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			printer := NewJSONIssuePrinter(buf)
+			scriptOutput := &bytes.Buffer{}
+
+			passed, err := RunSuites(
+				context.Background(),
+				[]string{"a", filepath.Join("b", "nested"), "c"},
+				jobs,
+				Options{
+					Logger:       log.New(io.Discard, "", 0),
+					ScriptOutput: scriptOutput,
+					Printers:     []IssuePrinter{printer},
+				},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(scriptOutput.String(), "Checking suite a") {
+				t.Fatalf("expected the output of the script of a, got: %q", scriptOutput.String())
+			}
+
+			if passed {
+				t.Fatal("expected the suites to fail")
+			}
+
+			if err := printer.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			var report struct {
+				Passed bool `json:"passed"`
+				Suites []struct {
+					Suite  string `json:"suite"`
+					Passed bool   `json:"passed"`
+				} `json:"suites"`
+				Checks struct {
+					Files map[string]json.RawMessage `json:"files"`
+				} `json:"checks"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+
+			type suiteResult struct {
+				Suite  string
+				Passed bool
+			}
+
+			got := []suiteResult{}
+			for _, suite := range report.Suites {
+				got = append(got, suiteResult{Suite: filepath.ToSlash(suite.Suite), Passed: suite.Passed})
+			}
+
+			expected := []suiteResult{
+				{Suite: "a", Passed: true},
+				{Suite: "b/nested", Passed: false},
+				{Suite: "c", Passed: false},
+			}
+
+			if !cmp.Equal(got, expected) {
+				t.Fatalf("unexpected suite results, diff: %s", cmp.Diff(expected, got))
+			}
+
+			if report.Passed {
+				t.Fatal("expected the report to fail")
+			}
+
+			if _, ok := report.Checks.Files[filepath.Join("b", "nested", "main.go")]; !ok {
+				t.Fatalf("expected the failures of b/nested/main.go in the report, got: %v", report.Checks.Files)
+			}
+		})
+	}
+}
+
+// TestRunSuites_OverlappingCodePaths runs two suites testing Autofix in-place
+// in the same code path in parallel. Without serializing them, the second
+// suite fails as the journal of the first one exists.
+func TestRunSuites_OverlappingCodePaths(t *testing.T) {
+	dir := filepath.Join("testdata", "suites_overlap")
+
+	original, err := os.ReadFile(filepath.Join(dir, "code", "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	printer := NewJSONIssuePrinter(io.Discard)
+	passed, err := RunSuites(
+		context.Background(),
+		[]string{filepath.Join(dir, "a"), filepath.Join(dir, "b")},
+		2,
+		Options{Printers: []IssuePrinter{printer}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, suite := range printer.report.Suites {
+		if suite.Error != "" {
+			t.Fatalf("unable to run the suite %s: %s", suite.Suite, suite.Error)
+		}
+	}
+
+	if !passed {
+		t.Fatal("expected the suites to pass")
+	}
+
+	restored, err := os.ReadFile(filepath.Join(dir, "code", "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(restored) != string(original) {
+		t.Fatalf("main.go was not restored, got: %q", restored)
+	}
+}
+
+func TestPathLock(t *testing.T) {
+	code, pkg, other := filepath.FromSlash("/code"), filepath.FromSlash("/code/pkg"), filepath.FromSlash("/other")
+
+	l := newPathLock()
+	l.lock(code, false)
+
+	if l.conflicts(pkg, false) {
+		t.Error("a reader conflicts with an overlapping reader")
+	}
+
+	if !l.conflicts(pkg, true) {
+		t.Error("a writer doesn't conflict with an overlapping reader")
+	}
+
+	l.unlock(code, false)
+	l.lock(pkg, true)
+
+	if !l.conflicts(code, false) {
+		t.Error("a reader doesn't conflict with an overlapping writer")
+	}
+
+	if l.conflicts(other, true) {
+		t.Error("a writer conflicts with a writer of another path")
+	}
+
+	l.unlock(pkg, true)
+
+	if l.conflicts(code, true) {
+		t.Error("a writer conflicts with the unlocked paths")
+	}
+}
+
+func TestIsSubPath(t *testing.T) {
+	tests := []struct {
+		parent, path string
+		want         bool
+	}{
+		{parent: "/code", path: "/code", want: true},
+		{parent: "/code", path: "/code/pkg", want: true},
+		{parent: "/code/pkg", path: "/code", want: false},
+		{parent: "/code", path: "/code2", want: false},
+		{parent: "/code", path: "/other/..code", want: false},
+	}
+
+	for _, tt := range tests {
+		parent, path := filepath.FromSlash(tt.parent), filepath.FromSlash(tt.path)
+		if got := isSubPath(parent, path); got != tt.want {
+			t.Errorf("isSubPath(%q, %q) = %v, want %v", parent, path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+package main
+
+func a() {
+	b := 10
+	b = b
+}
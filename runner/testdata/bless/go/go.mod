@@ -0,0 +1,3 @@
+module github.com/deepsourcelabs/SCATR/testdata/bless/go
+
+go 1.19
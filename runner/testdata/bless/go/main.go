@@ -0,0 +1,3 @@
+package main
+
+var foo int = 10
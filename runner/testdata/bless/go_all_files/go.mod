@@ -0,0 +1,3 @@
+module github.com/deepsourcelabs/SCATR/testdata/bless/go_all_files
+
+go 1.19
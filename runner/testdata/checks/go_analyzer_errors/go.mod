@@ -0,0 +1,3 @@
+module github.com/deepsourcelabs/SCATR/testdata/checks/go_analyzer_errors
+
+go 1.19
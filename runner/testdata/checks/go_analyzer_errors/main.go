@@ -0,0 +1,4 @@
+package main
+
+// [GO-C5001]: 9 "Redundant type in variable declaration"
+var foo int = 10
@@ -0,0 +1,3 @@
+module github.com/deepsourcelabs/SCATR/testdata/checks/go_end_positions
+
+go 1.19
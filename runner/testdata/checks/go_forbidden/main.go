@@ -0,0 +1,10 @@
+// scatr-check: GO-W1000
+package main
+
+import "fmt"
+
+func main() {
+	// [!GO-W1008]
+	fmt.Println("hello")
+	fmt.Println("world") // [GO-W1000]; [!GO-W1009]
+}
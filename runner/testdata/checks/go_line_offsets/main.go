@@ -0,0 +1,12 @@
+package main
+
+import "fmt"
+
+const query = `
+SELECT *
+FROM foo
+` // [GO-W1001]@-2: 1 "Wildcard select"; [GO-W1002]@L7; [GO-W1003]@L0
+
+func main() {
+	fmt.Println(query) // [GO-W1000]@+1; [GO-W1004]@+100
+}
@@ -0,0 +1,3 @@
+module github.com/deepsourcelabs/SCATR/testdata/checks/go_line_offsets_outside
+
+go 1.19
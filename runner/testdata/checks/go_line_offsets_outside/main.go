@@ -0,0 +1,9 @@
+package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("hello") // [!GO-W1000]@+100; [GO-W1001]@L0 x0
+}
+
+// [!GO-W1002]
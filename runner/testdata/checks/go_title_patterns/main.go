@@ -0,0 +1,10 @@
+package main
+
+// [GO-R1005]: 1 re"cyclomatic complexity \d+"
+func foo() {}
+
+// [GO-W1027]: glob"function * is unused"
+func bar() {}
+
+// [GO-R1005]: re"cyclomatic complexity [2-9]\d"
+func baz() {}
@@ -0,0 +1,4 @@
+// [no-unused-vars]: 7 "'a' is assigned a value but never used."
+const a = 10;
+
+console.log("Hello"); // [no-console]
@@ -0,0 +1,7 @@
+import os  # [F401]: 1 "'os' imported but unused"
+
+
+def main():
+    # [E711]: 11
+    if os is None:
+        pass
@@ -0,0 +1,7 @@
+def main():
+    url = "http://example.com/#anchor"  # [PY-W1000]: 5
+    query = 'SELECT * FROM users # [PY-W1001]'
+    doc = """
+    # [PY-W1002]
+    """
+    return url, query, doc  # [PY-W1003]
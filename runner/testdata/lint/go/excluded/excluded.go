@@ -0,0 +1,3 @@
+package excluded
+
+var foo = 10 // [GO-W1000]: x
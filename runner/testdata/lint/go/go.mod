@@ -0,0 +1,3 @@
+module github.com/deepsourcelabs/SCATR/testdata/lint/go
+
+go 1.19
@@ -0,0 +1,8 @@
+package main
+
+// scatr-ignore: VET-V0002
+func main() {
+	a := 10
+	a = a // [VET-V0002]: 2 "Useless assignment
+	_ = a // [GO-W1000]; [GO-W1001]:
+}
@@ -0,0 +1,3 @@
+module github.com/deepsourcelabs/SCATR/testdata/suites/.hidden
+
+go 1.19
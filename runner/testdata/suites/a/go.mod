@@ -0,0 +1,3 @@
+module github.com/deepsourcelabs/SCATR/testdata/suites/a
+
+go 1.19
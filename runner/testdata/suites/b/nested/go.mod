@@ -0,0 +1,3 @@
+module github.com/deepsourcelabs/SCATR/testdata/suites/b/nested
+
+go 1.19
@@ -0,0 +1,3 @@
+module github.com/deepsourcelabs/SCATR/testdata/suites_overlap/code
+
+go 1.19
@@ -0,0 +1,7 @@
+package main
+
+func main() {
+	a := 1
+	a = a
+	_ = a
+}
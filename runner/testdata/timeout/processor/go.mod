@@ -0,0 +1,3 @@
+module github.com/deepsourcelabs/SCATR/testdata/timeout/processor
+
+go 1.19
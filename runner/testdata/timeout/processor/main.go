@@ -0,0 +1,6 @@
+package main
+
+func main() {
+	a := 10
+	a = a
+}
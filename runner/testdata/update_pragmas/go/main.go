@@ -0,0 +1,16 @@
+// scatr-ignore: SCC-compile
+package main
+
+// [GO-C5001]: 5 "Old title"
+var foo int = 10
+
+func bar() {
+	a := 10 // a comment
+	a = a
+
+	// [VET-V0002]
+	a = a
+
+	// [VET-V0002]
+	a = a // [SCC-compile]
+}
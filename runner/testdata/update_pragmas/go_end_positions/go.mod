@@ -0,0 +1,3 @@
+module github.com/deepsourcelabs/SCATR/testdata/update_pragmas/go_end_positions
+
+go 1.19
@@ -0,0 +1,14 @@
+package main
+
+import "fmt"
+
+func main() {
+	// [GO-W1000]: 2-18 "Call"
+	fmt.Println("hello")
+	// [GO-W1001]: 2:+2:3 "Multi-line call"
+	fmt.Println(
+		"hello",
+	)
+	x := 1 // [GO-W1002]: 2-7 "Assignment"
+	_ = x
+}
@@ -0,0 +1,14 @@
+package main
+
+import "fmt"
+
+var foo int = 10 // a comment
+
+const query = `
+SELECT *
+FROM foo
+` // [GO-W1001]@-2: 1 "Wildcard select"; [GO-W1002]@L9: 2
+
+func main() {
+	fmt.Println(query, foo)
+}
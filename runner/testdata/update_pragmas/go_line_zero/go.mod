@@ -0,0 +1,3 @@
+module github.com/deepsourcelabs/SCATR/testdata/update_pragmas/go_line_zero
+
+go 1.19
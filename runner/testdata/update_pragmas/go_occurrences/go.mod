@@ -0,0 +1,3 @@
+module github.com/deepsourcelabs/SCATR/testdata/update_pragmas/go_occurrences
+
+go 1.19
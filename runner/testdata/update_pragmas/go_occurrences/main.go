@@ -0,0 +1,7 @@
+package main
+
+func main() {
+	a, b, c := 1, 2, 3 // [GO-W1000] x3
+	a, b = b, a        // [GO-W1000] x2
+	_ = c              // [GO-W1001] x1+; [GO-W1002] x0
+}
@@ -0,0 +1,5 @@
+package main
+
+var foo int = 10
+
+func main() {}
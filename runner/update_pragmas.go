@@ -0,0 +1,266 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/deepsourcelabs/SCATR/pragma"
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+)
+
+// UpdatePragmas runs the checks and rewrites the pragmas in the files so that
+// they match the analysis result. Only the lines where the pragmas don't match
+// the result are rewritten, and the pragmas of the issue codes which are not
+// checked in a file are kept as is. In case dryRun is true, the files are not
+// written, and the diff of each file is printed instead. It returns the paths
+// of the files which were (or would be) updated. The timeout is used for the
+// stages which don't have a timeout configured. The issues on the lines outside
+// a file are skipped with a warning.
+func UpdatePragmas(
+	ctx context.Context,
+	printer IssuePrinter,
+	files []string,
+	dryRun bool,
+	timeout time.Duration,
+) ([]string, error) {
+	config, err := ReadConfig(".scatr.toml")
+	if err != nil {
+		return nil, err
+	}
+	config.setDefaultTimeout(timeout)
+
+	if !config.TestChecks {
+		return nil, errors.New("checks are not configured")
+	}
+
+	includedFiles, err := normalizeFileList(ctx, files, config.resolvePath(config.CodePath))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := runChecks(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	pragmaFiles, err := readFiles(ctx, config, includedFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	res, _ := diffChecksResult(pragmaFiles, config.ExcludedDirs, includedFiles, result)
+
+	raised := make(map[string]map[int][]*Issue)
+	for _, iss := range result.Issues {
+		if isExcluded(iss.Position.fileNormalized, config.ExcludedDirs) {
+			continue
+		}
+
+		lines, ok := raised[iss.Position.fileNormalized]
+		if !ok {
+			lines = make(map[int][]*Issue)
+			raised[iss.Position.fileNormalized] = lines
+		}
+
+		lines[iss.Position.Start.Line] = append(lines[iss.Position.Start.Line], iss)
+	}
+
+	paths := make([]string, 0, len(res))
+	for path := range res {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var updated []string
+	for _, path := range paths {
+		file, ok := pragmaFiles[path]
+		if !ok {
+			continue
+		}
+
+		updates := make(map[int]*pragma.Pragma)
+		failed := append(append(res[path].Unexpected, res[path].NotRaised...), res[path].EndMismatch...)
+		failed = append(failed, res[path].CountMismatch...)
+		numLines := file.LineCount()
+		for _, iss := range failed {
+			line := iss.Position.Start.Line
+			if line < 1 || line > numLines {
+				printer.PrintWarning(fmt.Sprintf(
+					"Skipping the issue %s on line %d of %s, which is outside the file of %d lines",
+					iss.Code, line, relativePath(config.Dir, path), numLines,
+				))
+				continue
+			}
+
+			updates[line] = expectedPragma(file, line, raised[path][line])
+		}
+
+		content := file.UpdatePragmas(updates)
+		if content == file.Content {
+			continue
+		}
+
+		updated = append(updated, path)
+
+		if dryRun {
+			name := relativePath(config.Dir, path)
+			edits := myers.ComputeEdits(span.URIFromPath(path), file.Content, content)
+			printer.PrintUnifiedDiff(path, gotextdiff.ToUnified(name, name, file.Content, edits))
+			continue
+		}
+
+		stat, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		loggerFrom(ctx).Println("Updating the pragmas in", path)
+		err = os.WriteFile(path, []byte(content), stat.Mode())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return updated, nil
+}
+
+// expectedPragma returns the pragma for a line of the file which matches the
+// raised issues. The pragmas for the issue codes which are not checked in the
+// file are kept, along with the forbidden issue codes, which are not expected
+// even when raised. The ends of the issues are only written for the issue codes
+// whose pragmas expected an end, and the title patterns of the pragmas are kept
+// for the titles they match. The occurrence counts are kept if they match the
+// raised issues, and are updated otherwise.
+func expectedPragma(file *pragma.File, line int, issues []*Issue) *pragma.Pragma {
+	p := &pragma.Pragma{
+		Issues: make(map[string][]*pragma.Issue),
+		Hit:    make(map[string]bool),
+	}
+
+	checksEnd := make(map[string]bool)
+	patterns := make(map[string][]*pragma.Issue)
+	counts := make(map[string]*pragma.Count)
+	oldCounts := make(map[string]*pragma.Count)
+	if old, ok := file.Pragmas[line]; ok {
+		p.Forbidden = old.Forbidden
+
+		for code, pragmaIssues := range old.Issues {
+			if !shouldReport(file, code) {
+				p.Issues[code] = pragmaIssues
+				if count, ok := old.Counts[code]; ok {
+					counts[code] = count
+				}
+				continue
+			}
+
+			if count, ok := old.Counts[code]; ok {
+				oldCounts[code] = count
+			}
+
+			for _, issue := range pragmaIssues {
+				checksEnd[code] = checksEnd[code] || issue.End != nil
+				if issue.MessageType != pragma.MessageExact {
+					patterns[code] = append(patterns[code], issue)
+				}
+			}
+		}
+	}
+
+	type key struct {
+		code        string
+		column      int
+		message     string
+		messageType pragma.MessageType
+		end         pragma.End
+	}
+	seen := make(map[key]bool)
+	raised := make(map[string]int)
+
+	for _, iss := range issues {
+		if !shouldReport(file, iss.Code) || p.Forbidden[iss.Code] {
+			continue
+		}
+		raised[iss.Code]++
+
+		var end *pragma.End
+		if checksEnd[iss.Code] {
+			end = pragmaEnd(iss.Position)
+		}
+
+		message, messageType := iss.Title, pragma.MessageExact
+		var compiled *regexp.Regexp
+		for _, pattern := range patterns[iss.Code] {
+			if pattern.MatchesTitle(iss.Title) {
+				message, messageType, compiled = pattern.Message, pattern.MessageType, pattern.Pattern
+				break
+			}
+		}
+
+		k := key{code: iss.Code, column: iss.Position.Start.Column, message: message, messageType: messageType}
+		if end != nil {
+			k.end = *end
+		}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		p.Issues[iss.Code] = append(p.Issues[iss.Code], &pragma.Issue{
+			Column:      iss.Position.Start.Column,
+			Message:     message,
+			MessageType: messageType,
+			Pattern:     compiled,
+			End:         end,
+		})
+	}
+
+	for code, count := range oldCounts {
+		if n := raised[code]; count.Matches(n) {
+			// The count is kept even if the issue was not raised, like x0.
+			counts[code] = count
+			if _, ok := p.Issues[code]; !ok {
+				p.Issues[code] = []*pragma.Issue{}
+			}
+		} else if n > 0 {
+			counts[code] = &pragma.Count{N: n}
+		}
+	}
+
+	if len(counts) != 0 {
+		p.Counts = counts
+	}
+
+	for _, pragmaIssues := range p.Issues {
+		sort.SliceStable(pragmaIssues, func(i, j int) bool {
+			if pragmaIssues[i].Column != pragmaIssues[j].Column {
+				return pragmaIssues[i].Column < pragmaIssues[j].Column
+			}
+			return pragmaIssues[i].Message < pragmaIssues[j].Message
+		})
+	}
+
+	return p
+}
+
+// pragmaEnd returns the end of the position using the pragma syntax, or nil if
+// it can't be written in a pragma, like an end before the start.
+func pragmaEnd(position IssuePosition) *pragma.End {
+	start, end := position.Start, position.End
+	if start.Column == 0 || end == nil {
+		return nil
+	}
+
+	lines := end.Line - start.Line
+	if lines < 0 || (lines == 0 && end.Column < start.Column) {
+		return nil
+	}
+
+	return &pragma.End{Lines: lines, Column: end.Column}
+}
@@ -0,0 +1,108 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestUpdatePragmas is an integration test for rewriting the pragmas using the
+// analysis result. The test data is copied to a temporary directory, as the
+// files are updated in place.
+func TestUpdatePragmas(t *testing.T) {
+	tests := []struct {
+		name   string
+		dryRun bool
+	}{
+		{name: "go"},
+		{name: "go", dryRun: true},
+		{name: "go_end_positions"},
+		{name: "go_title_patterns"},
+		{name: "go_occurrences"},
+		{name: "go_line_offsets"},
+		{name: "go_line_zero"},
+		{name: "go_past_eof"},
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testDir := filepath.Join(cwd, "testdata", "update_pragmas")
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := copyTestData(t, filepath.Join(testDir, test.name))
+			if err := os.Chdir(dir); err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				if err := os.Chdir(cwd); err != nil {
+					t.Fatal(err)
+				}
+			}()
+
+			b, err := os.ReadFile("updated.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var expectedUpdated []string
+			if err := json.Unmarshal(b, &expectedUpdated); err != nil {
+				t.Fatal(err)
+			}
+
+			original := make(map[string]string)
+			for _, file := range expectedUpdated {
+				b, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				original[file] = string(b)
+			}
+
+			updated, err := UpdatePragmas(context.Background(), &NOPIssuePrinter{}, nil, test.dryRun, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			gotUpdated := []string{}
+			for _, file := range updated {
+				rel, err := filepath.Rel(dir, file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				gotUpdated = append(gotUpdated, rel)
+			}
+
+			if !cmp.Equal(gotUpdated, expectedUpdated) {
+				t.Fatalf("unexpected files updated, diff: %s", cmp.Diff(expectedUpdated, gotUpdated))
+			}
+
+			for _, file := range expectedUpdated {
+				got, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				expected := original[file]
+				if !test.dryRun {
+					b, err := os.ReadFile(file + ".expected")
+					if err != nil {
+						t.Fatal(err)
+					}
+					expected = string(b)
+				}
+
+				if string(got) != expected {
+					t.Fatalf("unexpected content of %s, diff: %s", file, cmp.Diff(expected, string(got)))
+				}
+			}
+		})
+	}
+}